@@ -10,6 +10,9 @@ import (
 	"path/filepath"
 	"strings"
 	"text/template"
+
+	"study1/internal/core/database"
+	_ "study1/internal/modules"
 )
 
 type EndpointInfo struct {
@@ -120,7 +123,7 @@ func main() {
 			module.Models = append(module.Models, models...)
 
 		case strings.HasSuffix(info.Name(), "model.go"):
-			models := parseModelFile(path)
+			models := registeredModels(moduleName, parseModelFile(path))
 			module.Models = append(module.Models, models...)
 
 		case strings.HasSuffix(info.Name(), "dto.go"):
@@ -157,6 +160,30 @@ func main() {
 	log.Println("✅ API documentation generated successfully!")
 }
 
+// registeredModels keeps the structs a module registered with
+// database.RegisterModels, dropping helper types declared next to them.
+// Modules that register no models keep everything.
+func registeredModels(moduleName string, models []ModelInfo) []ModelInfo {
+	registered := make(map[string]bool)
+	for _, reg := range database.ModelRegistrations() {
+		if reg.Module == moduleName {
+			registered[reg.TypeName] = true
+		}
+	}
+	if len(registered) == 0 {
+		return models
+	}
+
+	var out []ModelInfo
+	for _, model := range models {
+		if registered[model.Name] {
+			model.Module = moduleName
+			out = append(out, model)
+		}
+	}
+	return out
+}
+
 // ... (extractJSONTag, extractGormTag, extractTagValue functions tetap sama)
 
 // (previous generateExample with moduleName removed; keep later 2-arg version below)
@@ -2,59 +2,104 @@ package main
 
 import (
 	"bufio"
-	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
+
 	"study1/internal/core/config"
 	"study1/internal/core/database"
 	"study1/internal/core/database/migrations"
-	"study1/internal/modules/activity"
-	"study1/internal/modules/user"
+	_ "study1/internal/modules"
 
-	_ "github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 )
 
-func generateMigrations(db *gorm.DB) {
-	// Define models to generate migrations for
-	models := []interface{}{
-		&activity.ActivityLog{},
-		&user.User{},
-		// Add more models here as needed
-	}
-
+func migrationsDir() string {
 	// Get current working directory
 	cwd, err := os.Getwd()
 	if err != nil {
 		log.Fatalf("Failed to get current directory: %v", err)
 	}
 
-	migrationsDir := filepath.Join(cwd, "internal", "core", "database", "migrations", "generated")
+	return filepath.Join(cwd, "internal", "core", "database", "migrations", "generated")
+}
 
-	generator := database.NewMigrationGenerator(db, migrationsDir)
+func generateMigrations(db *gorm.DB) {
+	dir := migrationsDir()
+	generator, err := database.NewMigrationGenerator(db, dir)
+	if err != nil {
+		log.Fatalf("Failed to create migration generator: %v", err)
+	}
 
 	log.Println("🔄 Generating migrations from models...")
 
-	if err := generator.GenerateFromModels(models...); err != nil {
+	if err := generator.GenerateFromModels(database.Models()...); err != nil {
 		log.Fatalf("Failed to generate migrations: %v", err)
 	}
 
-	log.Printf("✅ Migrations generated successfully in: %s", migrationsDir)
+	if *dryRun {
+		log.Println("✅ Dry run: migrations printed, no files written")
+		return
+	}
+	log.Printf("✅ Migrations generated successfully in: %s", dir)
+}
+
+// diffMigrations compares the live schema with the models and writes
+// ALTER TABLE migrations for any differences.
+func diffMigrations(db *gorm.DB) {
+	dir := migrationsDir()
+	generator, err := database.NewMigrationGenerator(db, dir)
+	if err != nil {
+		log.Fatalf("Failed to create migration generator: %v", err)
+	}
+
+	log.Println("🔄 Comparing database schema with models...")
+
+	if err := generator.GenerateDiff(database.Models()...); err != nil {
+		log.Fatalf("Failed to generate diff migrations: %v", err)
+	}
+
+	if *dryRun {
+		log.Println("✅ Dry run: schema diff printed, no files written")
+		return
+	}
+	log.Printf("✅ Schema diff completed, migrations written to: %s", dir)
 }
 
+// Global flags, given before the command: migrate [--dry-run] [--yes] <command>
+var (
+	dryRun    = flag.Bool("dry-run", false, "print the SQL that would be executed without applying it")
+	assumeYes = flag.Bool("yes", false, "answer yes to prompts, e.g. creating a missing database (for CI)")
+)
+
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: migrate <command> \nCommands: generate, up, down, refresh, fresh")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: migrate [--dry-run] [--yes] <command> [args]")
+		fmt.Fprintln(os.Stderr, "Commands: generate, diff, up, down, drop, status, rollback, redo, refresh, fresh, seed")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(1)
 	}
 
-	command := os.Args[1]
+	command := flag.Arg(0)
+	args := flag.Args()[1:]
 
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// In a dry run, writes are recorded here instead of being executed.
+	var recorder *database.SQLRecorder
+	if *dryRun {
+		log.Println("Dry run: no changes will be applied")
+	}
+
 	// Before initializing GORM, check whether the database exists and offer to create it.
 	ok, err := checkAndOfferCreateDB(cfg.Database)
 	if err != nil {
@@ -69,6 +114,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	if *dryRun {
+		db.DB, recorder = database.NewDryRunDB(db.DB)
+	}
 
 	// Initialize migrator
 	migration := migrations.NewMigration(db.DB)
@@ -77,6 +125,9 @@ func main() {
 	case "generate", "gen":
 		generateMigrations(db.DB)
 
+	case "diff":
+		diffMigrations(db.DB)
+
 	case "up", "migrate":
 		log.Println("Running migrations...")
 		if err := migration.RunAll(); err != nil {
@@ -84,13 +135,66 @@ func main() {
 		}
 		log.Println("✅ Migrations completed successfully")
 
-	case "down", "drop":
+	case "down":
+		fs := flag.NewFlagSet("down", flag.ExitOnError)
+		fs.BoolVar(&migration.Force, "force", false, "remove the records of migrations without down SQL")
+		fs.Parse(args)
+
+		log.Println("Rolling back all migrations...")
+		rolledBack, err := migration.RollbackTo("")
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		log.Printf("✅ Rolled back %d migration(s)", len(rolledBack))
+
+	case "drop":
 		log.Println("Dropping all tables...")
 		if err := migration.DropAll(); err != nil {
 			log.Fatalf("Drop tables failed: %v", err)
 		}
 		log.Println("✅ All tables dropped successfully")
 
+	case "status":
+		printStatus(migration)
+
+	case "rollback":
+		fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		to := fs.String("to", "", "roll back every migration newer than this version")
+		fs.BoolVar(&migration.Force, "force", false, "remove the records of migrations without down SQL")
+		fs.Parse(args)
+
+		var rolledBack []database.MigrationRecord
+		if *to != "" {
+			log.Printf("Rolling back migrations newer than %s...", *to)
+			rolledBack, err = migration.RollbackTo(*to)
+		} else {
+			if *steps < 1 {
+				log.Fatal("--steps must be at least 1")
+			}
+			log.Printf("Rolling back %d migration(s)...", *steps)
+			rolledBack, err = migration.Rollback(*steps)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		log.Printf("✅ Rolled back %d migration(s)", len(rolledBack))
+
+	case "redo":
+		fs := flag.NewFlagSet("redo", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back and re-apply")
+		fs.BoolVar(&migration.Force, "force", false, "remove the records of migrations without down SQL")
+		fs.Parse(args)
+		if *steps < 1 {
+			log.Fatal("--steps must be at least 1")
+		}
+
+		log.Printf("Redoing %d migration(s)...", *steps)
+		if err := migration.Redo(*steps); err != nil {
+			log.Fatalf("Redo failed: %v", err)
+		}
+		log.Println("✅ Migrations redone successfully")
+
 	case "refresh":
 		log.Println("Refreshing database...")
 		if err := migration.Refresh(); err != nil {
@@ -99,56 +203,110 @@ func main() {
 		log.Println("✅ Database refreshed successfully")
 
 	case "fresh":
-		log.Println("Dropping all tables...")
-		if err := migration.DropAll(); err != nil {
-			log.Fatalf("Drop tables failed: %v", err)
+		fs := flag.NewFlagSet("fresh", flag.ExitOnError)
+		seed := fs.Bool("seed", false, "run all seeders after migrating")
+		fs.Parse(args)
+
+		// Refuse before dropping anything rather than after.
+		if *seed && config.IsProduction(cfg.Server.Environtment) {
+			log.Fatalf("Seeding failed: %v", database.ErrSeedingInProduction)
 		}
-		log.Println("Running migrations...")
-		if err := migration.RunAll(); err != nil {
-			log.Fatalf("Migration failed: %v", err)
+
+		log.Println("Dropping all tables and running migrations...")
+		if err := migration.Fresh(); err != nil {
+			log.Fatalf("Fresh failed: %v", err)
 		}
 		log.Println("✅ Database recreated successfully")
 
+		if *seed && *dryRun {
+			// Seeders read the live tables, which a dry run has not recreated.
+			log.Println("⚠️  Seeders are not previewed after fresh in a dry run")
+		} else if *seed {
+			runSeeders(db.DB, cfg.Server.Environtment)
+		}
+
+	case "seed":
+		runSeeders(db.DB, cfg.Server.Environtment, args...)
+
 	default:
-		log.Fatal("Invalid command. Available commands: generate, up, down, refresh, fresh")
+		log.Fatal("Invalid command. Available commands: generate, diff, up, down, drop, status, rollback, redo, refresh, fresh, seed")
+	}
+
+	if recorder != nil {
+		printStatements(recorder.Statements())
+	}
+}
+
+// printStatements prints the SQL recorded during a dry run, in execution order.
+func printStatements(statements []string) {
+	fmt.Printf("-- Dry run: %d statement(s) would be executed\n", len(statements))
+	for _, stmt := range statements {
+		fmt.Printf("%s;\n", stmt)
+	}
+}
+
+// runSeeders runs the named seeders, or all registered seeders when none are given.
+func runSeeders(db *gorm.DB, env string, names ...string) {
+	log.Println("Seeding database...")
+	if err := database.RunSeeders(db, env, names...); err != nil {
+		log.Fatalf("Seeding failed: %v", err)
 	}
+	log.Println("✅ Database seeded successfully")
+}
+
+// printStatus prints every known migration with its applied time.
+func printStatus(migration *migrations.Migration) {
+	statuses, err := migration.Status()
+	if err != nil {
+		log.Fatalf("Failed to read migration status: %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tSTATUS")
+	pending := 0
+	for _, st := range statuses {
+		appliedAt := "-"
+		status := "Pending"
+		if st.Applied {
+			appliedAt = st.AppliedAt.Format("2006-01-02 15:04:05")
+			status = "Applied"
+			if st.Missing {
+				status = "Applied (missing source)"
+			}
+		} else {
+			pending++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", st.Version, st.Name, appliedAt, status)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d migration(s), %d pending\n", len(statuses), pending)
 }
 
 // checkAndOfferCreateDB checks if the configured database exists. If not, it prompts
 // the user whether to create it. Returns true if the DB exists (or was created),
 // false if the DB does not exist and user chose not to create it.
 func checkAndOfferCreateDB(cfg config.DatabaseConfig) (bool, error) {
-	// connect without specifying database
-	dsn := cfg.GetDSNNoDB()
-
-	sqlDB, err := sql.Open("mysql", dsn)
+	exists, err := database.DatabaseExists(cfg)
 	if err != nil {
 		return false, err
 	}
-	defer sqlDB.Close()
-
-	if err := sqlDB.Ping(); err != nil {
-		return false, fmt.Errorf("cannot connect to DB server: %w", err)
+	if exists {
+		return true, nil
 	}
 
-	// check existence
-	var name string
-	row := sqlDB.QueryRow("SELECT SCHEMA_NAME FROM information_schema.schemata WHERE schema_name = ?", cfg.Name)
-	if err := row.Scan(&name); err == nil {
-		// exists
-		return true, nil
-	} else if err != sql.ErrNoRows {
-		return false, err
+	// A dry run cannot connect to a database that does not exist yet, so the
+	// preview stops at the statement that would create it.
+	if *dryRun {
+		log.Printf("Database '%s' does not exist; migrations can be previewed once it is created", cfg.Name)
+		if stmt := database.CreateDatabaseSQL(cfg); stmt != "" {
+			printStatements([]string{strings.TrimSuffix(stmt, ";")})
+		}
+		return false, nil
 	}
 
-	// Not exists: prompt
-	fmt.Printf("Database '%s' does not exist. Create it now? (y/N): ", cfg.Name)
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(strings.ToLower(input))
-	if input == "y" || input == "yes" {
-		createStmt := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;", cfg.Name)
-		if _, err := sqlDB.Exec(createStmt); err != nil {
+	if *assumeYes || confirm(fmt.Sprintf("Database '%s' does not exist. Create it now?", cfg.Name)) {
+		if err := database.CreateDatabase(cfg); err != nil {
 			return false, fmt.Errorf("failed to create database: %w", err)
 		}
 		fmt.Printf("Database '%s' created successfully.\n", cfg.Name)
@@ -158,3 +316,12 @@ func checkAndOfferCreateDB(cfg config.DatabaseConfig) (bool, error) {
 	// user chose not to create
 	return false, nil
 }
+
+// confirm asks a yes/no question on stdin.
+func confirm(question string) bool {
+	fmt.Printf("%s (y/N): ", question)
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+	return input == "y" || input == "yes"
+}
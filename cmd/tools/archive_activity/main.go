@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"study1/internal/core/config"
+	"study1/internal/core/database"
+	"study1/internal/modules/activity"
+)
+
+// archive_activity writes activity logs older than a cutoff to a gzipped
+// NDJSON file and then deletes them in chunks. Rows are only deleted once the
+// archive file is complete.
+func main() {
+	cfg := config.LoadConfig()
+
+	olderThanDays := flag.Int("older-than-days", cfg.Activity.RetentionDays, "archive logs older than this many days")
+	before := flag.String("before", "", "archive logs created before this date (2006-01-02 or RFC3339); overrides --older-than-days")
+	dir := flag.String("dir", "archives", "directory to write archive files to")
+	chunkSize := flag.Int("chunk-size", 1000, "rows read and deleted per query")
+	keep := flag.Bool("keep", false, "write the archive without deleting the archived rows")
+	flag.Parse()
+
+	cutoff, err := resolveCutoff(*before, *olderThanDays)
+	if err != nil {
+		log.Fatalf("invalid cutoff: %v", err)
+	}
+	if *chunkSize < 1 {
+		log.Fatal("--chunk-size must be at least 1")
+	}
+
+	db, err := database.NewDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v", err)
+	}
+	defer db.Close()
+	repo := activity.NewActivityRepository(db)
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		log.Fatalf("failed to create archive dir: %v", err)
+	}
+	path := filepath.Join(*dir, fmt.Sprintf("activity_logs_before_%s_%s.ndjson.gz",
+		cutoff.Format("20060102T150405"), time.Now().Format("20060102T150405")))
+
+	count, maxID, err := writeArchive(path, &repo, cutoff, *chunkSize)
+	if err != nil {
+		log.Fatalf("archive failed: %v", err)
+	}
+	if count == 0 {
+		fmt.Printf("no activity logs created before %s\n", cutoff.Format(time.RFC3339))
+		return
+	}
+	fmt.Printf("archived %d activity log(s) to %s\n", count, path)
+
+	if *keep {
+		return
+	}
+
+	// Only rows that made it into the archive are deleted.
+	var deleted int64
+	for {
+		n, err := repo.DeleteCreatedBefore(cutoff, maxID, *chunkSize)
+		deleted += n
+		if err != nil {
+			log.Fatalf("delete failed after %d row(s): %v", deleted, err)
+		}
+		if n < int64(*chunkSize) {
+			break
+		}
+	}
+	fmt.Printf("deleted %d activity log(s)\n", deleted)
+}
+
+// resolveCutoff returns the time before which logs are archived.
+func resolveCutoff(before string, olderThanDays int) (time.Time, error) {
+	if before != "" {
+		if t, err := time.ParseInLocation("2006-01-02", before, time.Local); err == nil {
+			return t, nil
+		}
+		return time.Parse(time.RFC3339, before)
+	}
+	if olderThanDays < 1 {
+		return time.Time{}, fmt.Errorf("--older-than-days must be at least 1 (ACTIVITY_RETENTION_DAYS is %d)", olderThanDays)
+	}
+	return time.Now().AddDate(0, 0, -olderThanDays), nil
+}
+
+// writeArchive streams every log created before cutoff into a gzipped NDJSON
+// file at path. It writes to a temporary file and renames it once complete,
+// and returns the number of rows and the highest ID archived.
+func writeArchive(path string, repo *activity.ActivityRepository, cutoff time.Time, chunkSize int) (int, uint, error) {
+	tmp := path + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	zw := gzip.NewWriter(f)
+	bw := bufio.NewWriter(zw)
+	enc := json.NewEncoder(bw)
+
+	var count int
+	var lastID uint
+	for {
+		rows, err := repo.FindCreatedBefore(cutoff, lastID, chunkSize)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return 0, 0, err
+			}
+			lastID = row.ID
+		}
+		count += len(rows)
+		if len(rows) < chunkSize {
+			break
+		}
+	}
+	if count == 0 {
+		return 0, 0, nil
+	}
+
+	if err := bw.Flush(); err != nil {
+		return 0, 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, 0, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, 0, err
+	}
+	return count, lastID, nil
+}
@@ -1,32 +1,285 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"study1/internal/core/config"
 	"study1/internal/core/database"
 	"study1/internal/modules/activity"
+
+	"gorm.io/gorm"
 )
 
+// options holds the parsed command-line flags.
+type options struct {
+	limit    int
+	since    time.Time
+	until    time.Time
+	statuses []statusRange
+	methods  []string
+	path     string
+	user     *uint
+	slowMs   int64
+	follow   bool
+	interval time.Duration
+	output   string
+}
+
 func main() {
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
 	cfg := config.LoadConfig()
-	db, err := database.NewDB(cfg.Database)
+	// Read-only: never create the database like the API and migrate do.
+	db, err := database.OpenDB(cfg.Database)
 	if err != nil {
 		log.Fatalf("failed to connect db: %v", err)
 	}
+	defer db.Close()
 
+	out, err := newPrinter(opts.output, os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Latest rows first, printed oldest to newest like tail.
 	var rows []activity.ActivityLog
-	if err := db.Order("created_at desc").Limit(10).Find(&rows).Error; err != nil {
+	if err := applyFilters(db.DB, opts).Order("id desc").Limit(opts.limit).Find(&rows).Error; err != nil {
 		log.Fatalf("query failed: %v", err)
 	}
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
 
-	if len(rows) == 0 {
+	if len(rows) == 0 && !opts.follow && opts.output == "table" {
 		fmt.Println("no activity logs found")
 		return
 	}
+	if err := out.print(rows); err != nil {
+		log.Fatalf("write failed: %v", err)
+	}
+	if !opts.follow {
+		return
+	}
+
+	var lastID uint
+	if len(rows) > 0 {
+		lastID = rows[len(rows)-1].ID
+	} else if err := db.Model(&activity.ActivityLog{}).Select("COALESCE(MAX(id), 0)").Scan(&lastID).Error; err != nil {
+		log.Fatalf("query failed: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := follow(ctx, db.DB, opts, out, lastID); err != nil {
+		log.Fatalf("follow failed: %v", err)
+	}
+}
+
+// follow polls for rows newer than lastID until ctx is done. Rows are
+// written by the API in batches, so they appear up to one flush interval late.
+func follow(ctx context.Context, db *gorm.DB, opts options, out printer, lastID uint) error {
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		var rows []activity.ActivityLog
+		if err := applyFilters(db, opts).Where("id > ?", lastID).Order("id").Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if err := out.print(rows); err != nil {
+			return err
+		}
+		lastID = rows[len(rows)-1].ID
+	}
+}
+
+func parseFlags(args []string) (options, error) {
+	fs := flag.NewFlagSet("inspect_activity", flag.ContinueOnError)
+	limit := fs.Int("limit", 10, "number of most recent rows to show")
+	since := fs.String("since", "", "only rows at or after this time: a duration ago (15m, 2h), a date (2006-01-02) or RFC3339")
+	until := fs.String("until", "", "only rows before this time, same formats as --since")
+	status := fs.String("status", "", "status codes, classes or ranges, comma-separated (500, 5xx, 400-499)")
+	method := fs.String("method", "", "HTTP methods, comma-separated (GET,POST)")
+	path := fs.String("path", "", "glob matched against the requested path (/api/v1/users/*)")
+	user := fs.Uint("user", 0, "only rows for this user ID")
+	slowMs := fs.Int64("slow-ms", 0, "only requests that took at least this many milliseconds")
+	followFlag := fs.Bool("follow", false, "keep printing new rows as they are written")
+	interval := fs.Duration("interval", time.Second, "poll interval for --follow")
+	output := fs.String("output", "table", "output format: table, json (one object per line) or csv")
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	opts := options{
+		limit:    *limit,
+		path:     *path,
+		slowMs:   *slowMs,
+		follow:   *followFlag,
+		interval: *interval,
+		output:   *output,
+	}
+	if opts.limit < 1 {
+		return opts, fmt.Errorf("--limit must be at least 1")
+	}
+	if opts.interval <= 0 {
+		return opts, fmt.Errorf("--interval must be positive")
+	}
+
+	now := time.Now()
+	var err error
+	if opts.since, err = parseTime(*since, now); err != nil {
+		return opts, fmt.Errorf("--since: %w", err)
+	}
+	if opts.until, err = parseTime(*until, now); err != nil {
+		return opts, fmt.Errorf("--until: %w", err)
+	}
+	if opts.follow && !opts.until.IsZero() {
+		// No row written from now on would match.
+		return opts, fmt.Errorf("--follow cannot be combined with --until")
+	}
+	if opts.statuses, err = parseStatuses(*status); err != nil {
+		return opts, fmt.Errorf("--status: %w", err)
+	}
+	for _, m := range strings.Split(*method, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			opts.methods = append(opts.methods, strings.ToUpper(m))
+		}
+	}
+	if *user != 0 {
+		id := uint(*user)
+		opts.user = &id
+	}
+	return opts, nil
+}
+
+// applyFilters adds the WHERE clauses selected by opts.
+func applyFilters(db *gorm.DB, opts options) *gorm.DB {
+	query := db.Model(&activity.ActivityLog{})
+	if !opts.since.IsZero() {
+		query = query.Where("created_at >= ?", opts.since)
+	}
+	if !opts.until.IsZero() {
+		query = query.Where("created_at < ?", opts.until)
+	}
+	if len(opts.statuses) > 0 {
+		var conds []string
+		var args []interface{}
+		for _, r := range opts.statuses {
+			conds = append(conds, "status BETWEEN ? AND ?")
+			args = append(args, r.min, r.max)
+		}
+		query = query.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+	if len(opts.methods) > 0 {
+		query = query.Where("method IN ?", opts.methods)
+	}
+	if opts.path != "" {
+		query = query.Where("raw_path LIKE ? ESCAPE '!'", globToLike(opts.path))
+	}
+	if opts.user != nil {
+		query = query.Where("user_id = ?", *opts.user)
+	}
+	if opts.slowMs > 0 {
+		query = query.Where("latency_ms >= ?", opts.slowMs)
+	}
+	return query
+}
+
+// parseTime accepts a duration before now, a date or an RFC3339 timestamp.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// statusRange is an inclusive range of status codes.
+type statusRange struct {
+	min, max int
+}
+
+// parseStatuses parses a comma-separated list of codes (404), classes (5xx)
+// and ranges (400-499).
+func parseStatuses(value string) ([]statusRange, error) {
+	var ranges []statusRange
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		if len(part) == 3 && strings.HasSuffix(part, "xx") {
+			class, err := strconv.Atoi(part[:1])
+			if err != nil || class < 1 || class > 5 {
+				return nil, fmt.Errorf("invalid status class %q", part)
+			}
+			ranges = append(ranges, statusRange{class * 100, class*100 + 99})
+			continue
+		}
+
+		lo, hi, isRange := strings.Cut(part, "-")
+		min, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status %q", part)
+		}
+		max := min
+		if isRange {
+			if max, err = strconv.Atoi(hi); err != nil || max < min {
+				return nil, fmt.Errorf("invalid status range %q", part)
+			}
+		}
+		ranges = append(ranges, statusRange{min, max})
+	}
+	return ranges, nil
+}
 
-	for _, r := range rows {
-		fmt.Printf("%s %s -> %d (%dms) ip=%s user=%v\n", r.Method, r.Path, r.Status, r.LatencyMs, r.IP, r.UserID)
+// globToLike converts a glob (* and ?) to a LIKE pattern escaped with '!',
+// an escape character that works the same on MySQL, Postgres and SQLite.
+func globToLike(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteByte('%')
+		case '?':
+			b.WriteByte('_')
+		case '%', '_', '!':
+			b.WriteByte('!')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
 	}
+	return b.String()
 }
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"study1/internal/modules/activity"
+)
+
+// printer writes activity logs in one output format. print may be called
+// repeatedly in follow mode.
+type printer interface {
+	print(rows []activity.ActivityLog) error
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "table":
+		return &tablePrinter{w: w}, nil
+	case "json":
+		return &jsonPrinter{enc: json.NewEncoder(w)}, nil
+	case "csv":
+		return &csvPrinter{w: csv.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown output %q (table, json or csv)", format)
+}
+
+var columns = []string{"time", "request_id", "method", "status", "latency_ms", "path", "query", "user_id", "ip", "errors"}
+
+func fields(r activity.ActivityLog) []string {
+	user := ""
+	if r.UserID != nil {
+		user = strconv.FormatUint(uint64(*r.UserID), 10)
+	}
+	path := r.RawPath
+	if path == "" {
+		// Rows recorded before raw paths were stored only have the route.
+		path = r.Path
+	}
+	return []string{
+		r.CreatedAt.Local().Format(time.RFC3339),
+		r.RequestID,
+		r.Method,
+		strconv.Itoa(r.Status),
+		strconv.FormatInt(r.LatencyMs, 10),
+		path,
+		r.Query,
+		user,
+		r.IP,
+		r.Errors,
+	}
+}
+
+// tablePrinter uses fixed column widths rather than a tabwriter so rows
+// printed in follow mode line up with earlier ones.
+type tablePrinter struct {
+	w             io.Writer
+	headerWritten bool
+}
+
+const tableRow = "%-25s  %-7s  %-6s  %8s  %-40s  %-6s  %-15s  %s\n"
+
+func (p *tablePrinter) print(rows []activity.ActivityLog) error {
+	if !p.headerWritten {
+		if _, err := fmt.Fprintf(p.w, tableRow, "TIME", "METHOD", "STATUS", "LATENCY", "PATH", "USER", "IP", "REQUEST ID"); err != nil {
+			return err
+		}
+		p.headerWritten = true
+	}
+	for _, r := range rows {
+		f := fields(r)
+		path := f[5]
+		if f[6] != "" {
+			path += "?" + f[6]
+		}
+		user := f[7]
+		if user == "" {
+			user = "-"
+		}
+		if _, err := fmt.Fprintf(p.w, tableRow, f[0], f[2], f[3], f[4]+"ms", path, user, f[8], f[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonPrinter struct {
+	enc *json.Encoder
+}
+
+func (p *jsonPrinter) print(rows []activity.ActivityLog) error {
+	for _, r := range rows {
+		if err := p.enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type csvPrinter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (p *csvPrinter) print(rows []activity.ActivityLog) error {
+	if !p.headerWritten {
+		if err := p.w.Write(columns); err != nil {
+			return err
+		}
+		p.headerWritten = true
+	}
+	for _, r := range rows {
+		if err := p.w.Write(fields(r)); err != nil {
+			return err
+		}
+	}
+	p.w.Flush()
+	return p.w.Error()
+}
@@ -1,17 +1,33 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
 	"study1/internal/core/config"
 	"study1/internal/core/database"
 	"study1/internal/core/http"
-	"study1/internal/modules/activity"
-	"study1/internal/modules/user"
+	"study1/internal/core/module"
+	_ "study1/internal/modules"
+
+	// Registers the generated migrations, which Module.Migrations reports.
+	_ "study1/internal/core/database/migrations/generated"
 )
 
 type App struct {
-	config *config.Config
-	server *http.Server
-	db     *database.DB
+	config  *config.Config
+	server  *http.Server
+	db      *database.DB
+	modules *module.Registry
+
+	shutdownOnce sync.Once
+	shutdownErr  error
 }
 
 func New(cfg *config.Config) (*App, error) {
@@ -20,23 +36,92 @@ func New(cfg *config.Config) (*App, error) {
 		return nil, err
 	}
 
-	// Initialize modules
-	userModule := user.NewUserModule(db)
-	activityModule := activity.NewActivityModule(db)
+	// Initialize every registered module (see internal/modules/modules.go)
+	modules := module.NewRegistry()
+	for _, factory := range module.Factories() {
+		if err := modules.Add(factory(cfg, db)); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	ordered, err := modules.Resolve()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 
-	// Pass semua modules ke server (provide DB for middleware)
-	server := http.NewServer(cfg, db, userModule, activityModule) //, otherModule, anotherModule)
+	// Pass semua modules ke server
+	registrars := make([]http.RouteRegistrar, 0, len(ordered))
+	for _, m := range ordered {
+		registrars = append(registrars, m)
+	}
+	server := http.NewServer(cfg, modules, registrars...)
 
 	return &App{
-		config: cfg,
-		server: server,
-		db:     db,
+		config:  cfg,
+		server:  server,
+		db:      db,
+		modules: modules,
 	}, nil
 }
 
+// Start starts the modules in dependency order and serves HTTP until the
+// process receives SIGINT/SIGTERM, the server fails or Shutdown is called.
+// It then shuts the app down within Server.ShutdownTimeout.
 func (a *App) Start() error {
+	if err := a.modules.Start(context.Background()); err != nil {
+		a.db.Close()
+		return err
+	}
+
 	// Start the HTTP server
-	return a.server.Start(a.config.Server.Port)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- a.server.Start(a.config.Server.Port)
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	var err error
+	select {
+	case err = <-serveErr:
+		// Server failed, or Shutdown was called from elsewhere.
+	case sig := <-quit:
+		log.Printf("Received %s, shutting down...", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Server.ShutdownTimeout)
+	defer cancel()
+	if shutdownErr := a.Shutdown(ctx); shutdownErr != nil {
+		err = errors.Join(err, shutdownErr)
+	}
+	return err
+}
+
+// Shutdown drains in-flight HTTP requests, stops the modules in reverse start
+// order so they can flush background work, and closes the database. Work that
+// has not finished when ctx is done is abandoned. It is safe to call more
+// than once; later calls return the first result.
+func (a *App) Shutdown(ctx context.Context) error {
+	a.shutdownOnce.Do(func() {
+		var errs []error
+		if err := a.server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown http server: %w", err))
+		}
+		if err := a.modules.Stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		if err := a.db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close database: %w", err))
+		}
+		a.shutdownErr = errors.Join(errs...)
+		if a.shutdownErr == nil {
+			log.Println("✅ Application stopped gracefully")
+		}
+	})
+	return a.shutdownErr
 }
 
 func (a *App) GetDB() *database.DB {
@@ -1,12 +1,18 @@
 package config
 
 import (
+	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Activity ActivityConfig
 }
 
 type ServerConfig struct {
@@ -18,6 +24,27 @@ type ServerConfig struct {
 	Port         string
 	BasePath     string
 	URL          string
+
+	// HTTP server timeouts. ShutdownTimeout bounds how long in-flight
+	// requests and background work may take to drain on shutdown.
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+}
+
+// ActivityConfig controls how request activity logs are buffered and written.
+type ActivityConfig struct {
+	BufferSize    int
+	BatchSize     int
+	FlushInterval time.Duration
+	// RedactFields are body fields and query parameters whose values are
+	// never stored.
+	RedactFields []string
+	// RetentionDays is how long activity logs are kept; 0 keeps them forever.
+	RetentionDays  int
+	PruneInterval  time.Duration
+	PruneBatchSize int
 }
 
 type DatabaseConfig struct {
@@ -40,6 +67,11 @@ func LoadConfig() *Config {
 			Port:         getEnv("APP_PORT", "8080"),
 			BasePath:     getEnv("APP_BASE_PATH", "/api/v1/"),
 			URL:          getEnv("APP_URL", "http://localhost:8080/api/v1/"),
+
+			ReadTimeout:     getEnvDuration("APP_READ_TIMEOUT", 15*time.Second),
+			WriteTimeout:    getEnvDuration("APP_WRITE_TIMEOUT", 30*time.Second),
+			IdleTimeout:     getEnvDuration("APP_IDLE_TIMEOUT", 60*time.Second),
+			ShutdownTimeout: getEnvDuration("APP_SHUTDOWN_TIMEOUT", 15*time.Second),
 		},
 		Database: DatabaseConfig{
 			Driver:   getEnv("DB_DRIVER", "mysql"),
@@ -49,6 +81,15 @@ func LoadConfig() *Config {
 			User:     getEnv("DB_USER", "root"),
 			Password: getEnv("DB_PASSWORD", ""),
 		},
+		Activity: ActivityConfig{
+			BufferSize:     getEnvInt("ACTIVITY_BUFFER_SIZE", 1024),
+			BatchSize:      getEnvInt("ACTIVITY_BATCH_SIZE", 100),
+			FlushInterval:  getEnvDuration("ACTIVITY_FLUSH_INTERVAL", time.Second),
+			RedactFields:   getEnvList("ACTIVITY_REDACT_FIELDS", "password,email,token,secret,api_key"),
+			RetentionDays:  getEnvInt("ACTIVITY_RETENTION_DAYS", 90),
+			PruneInterval:  getEnvDuration("ACTIVITY_PRUNE_INTERVAL", time.Hour),
+			PruneBatchSize: getEnvInt("ACTIVITY_PRUNE_BATCH_SIZE", 1000),
+		},
 	}
 }
 
@@ -60,12 +101,66 @@ func getEnv(key string, defaultVal string) string {
 	return defaultVal
 }
 
+// getEnvInt reads an integer. Invalid values fall back to the default with a
+// warning.
+func getEnvInt(key string, defaultVal int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultVal
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("⚠️  Invalid %s=%q, using %d", key, value, defaultVal)
+		return defaultVal
+	}
+	return n
+}
+
+// getEnvDuration reads a duration such as "30s" or "1m". Invalid values fall
+// back to the default with a warning.
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultVal
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("⚠️  Invalid %s=%q, using %s", key, value, defaultVal)
+		return defaultVal
+	}
+	return d
+}
+
+// getEnvList reads a comma-separated list, dropping empty entries.
+func getEnvList(key string, defaultVal string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, defaultVal), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// IsProduction reports whether env names a production environment. Case and
+// surrounding spaces are ignored and "prod" counts too, so production guards
+// are not bypassed by APP_ENVIRONMENT=Production.
+func IsProduction(env string) bool {
+	switch strings.ToLower(strings.TrimSpace(env)) {
+	case "production", "prod":
+		return true
+	}
+	return false
+}
+
 func (dbCfg DatabaseConfig) GetDSN() string {
 	switch dbCfg.Driver {
 	case "mysql":
 		return dbCfg.User + ":" + dbCfg.Password + "@tcp(" + dbCfg.Host + ":" + dbCfg.Port + ")/" + dbCfg.Name + "?charset=utf8mb4&parseTime=True&loc=Asia%2FJakarta"
 	case "postgres":
 		return "host=" + dbCfg.Host + " port=" + dbCfg.Port + " user=" + dbCfg.User + " password=" + dbCfg.Password + " dbname=" + dbCfg.Name + " sslmode=disable"
+	case "sqlite":
+		return dbCfg.SQLitePath()
 	default:
 		return ""
 	}
@@ -76,8 +171,18 @@ func (dbCfg DatabaseConfig) GetDSNNoDB() string {
 	case "mysql":
 		return dbCfg.User + ":" + dbCfg.Password + "@tcp(" + dbCfg.Host + ":" + dbCfg.Port + ")/?charset=utf8mb4&parseTime=True&loc=Asia%2FJakarta"
 	case "postgres":
-		return "host=" + dbCfg.Host + " port=" + dbCfg.Port + " user=" + dbCfg.User + " password=" + dbCfg.Password + " sslmode=disable"
+		// Postgres always connects to a database; use the maintenance one.
+		return "host=" + dbCfg.Host + " port=" + dbCfg.Port + " user=" + dbCfg.User + " password=" + dbCfg.Password + " dbname=postgres sslmode=disable"
 	default:
 		return ""
 	}
 }
+
+// SQLitePath returns the database file used by the sqlite driver. DB_NAME is
+// treated as a file path; a ".db" extension is added when none is given.
+func (dbCfg DatabaseConfig) SQLitePath() string {
+	if dbCfg.Name == ":memory:" || filepath.Ext(dbCfg.Name) != "" {
+		return dbCfg.Name
+	}
+	return dbCfg.Name + ".db"
+}
@@ -1,13 +1,10 @@
 package database
 
 import (
-	"database/sql"
 	"fmt"
 	"study1/internal/core/config"
 	"study1/internal/core/types"
 
-	_ "github.com/go-sql-driver/mysql"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
@@ -15,43 +12,61 @@ type DB struct {
 	*gorm.DB
 }
 
+// NewDB opens a connection using the dialect selected by cfg.Driver
+// (mysql, postgres or sqlite), creating the database first if needed.
 func NewDB(cfg config.DatabaseConfig) (*DB, error) {
 	// Ensure the database exists (create if missing). This is idempotent.
 	if err := ensureDatabase(cfg); err != nil {
 		return nil, fmt.Errorf("ensure database: %w", err)
 	}
+	return OpenDB(cfg)
+}
+
+// OpenDB opens a connection to an existing database, for tools that must
+// not create one. A missing SQLite file is reported rather than created.
+func OpenDB(cfg config.DatabaseConfig) (*DB, error) {
+	if cfg.Driver == DriverSQLite {
+		exists, err := DatabaseExists(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("database %s does not exist", cfg.SQLitePath())
+		}
+	}
+
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	dsn := cfg.GetDSN()
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
 	return &DB{DB: db}, nil
 }
 
-// ensureDatabase connects to the MySQL server without selecting a database
-// and creates the configured database if it does not exist. This mirrors
-// behavior in frameworks that offer to create the DB automatically.
-func ensureDatabase(cfg config.DatabaseConfig) error {
-	dsn := cfg.GetDSNNoDB()
-	sqlDB, err := sql.Open("mysql", dsn)
+// Close closes the underlying connection pool.
+func (db *DB) Close() error {
+	sqlDB, err := db.DB.DB()
 	if err != nil {
 		return err
 	}
-	defer sqlDB.Close()
+	return sqlDB.Close()
+}
 
-	// Try pinging to ensure connection
-	if err := sqlDB.Ping(); err != nil {
+// ensureDatabase creates the configured database if it does not exist. This
+// mirrors behavior in frameworks that offer to create the DB automatically.
+func ensureDatabase(cfg config.DatabaseConfig) error {
+	exists, err := DatabaseExists(cfg)
+	if err != nil {
 		return err
 	}
-
-	// Create database if it does not exist
-	createStmt := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;", cfg.Name)
-	if _, err := sqlDB.Exec(createStmt); err != nil {
-		return fmt.Errorf("create database %s: %w", cfg.Name, err)
+	if exists {
+		return nil
 	}
-
-	return nil
+	return CreateDatabase(cfg)
 }
 
 // NewQueryBuilder creates a new QueryBuilder for the specified model type T.
@@ -61,5 +76,6 @@ func NewQueryBuilder[T any](db *gorm.DB, params types.QueryParams) *QueryBuilder
 		DB:     db.Model(&model),
 		Model:  model,
 		Params: params,
+		root:   db,
 	}
 }
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"study1/internal/core/config"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Supported values for DB_DRIVER.
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// newDialector returns the GORM dialector for the configured driver.
+func newDialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	dsn := cfg.GetDSN()
+	switch cfg.Driver {
+	case DriverMySQL:
+		return mysql.Open(dsn), nil
+	case DriverPostgres:
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// Location returns the time zone db stores times in: the loc parameter of a
+// MySQL DSN, as MySQL DATETIME columns hold wall-clock times without a zone.
+// PostgreSQL and SQLite store absolute times, reported as UTC.
+func Location(db *gorm.DB) *time.Location {
+	if d, ok := db.Dialector.(*mysql.Dialector); ok && d.DSNConfig != nil && d.DSNConfig.Loc != nil {
+		return d.DSNConfig.Loc
+	}
+	return time.UTC
+}
+
+// openServer connects to the database server without selecting the
+// configured database. It is not used for sqlite, which has no server.
+func openServer(cfg config.DatabaseConfig) (*sql.DB, error) {
+	var driverName string
+	switch cfg.Driver {
+	case DriverMySQL:
+		driverName = "mysql"
+	case DriverPostgres:
+		driverName = "pgx"
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+
+	sqlDB, err := sql.Open(driverName, cfg.GetDSNNoDB())
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("cannot connect to DB server: %w", err)
+	}
+	return sqlDB, nil
+}
+
+// DatabaseExists reports whether the configured database already exists.
+// For sqlite this checks for the database file on disk.
+func DatabaseExists(cfg config.DatabaseConfig) (bool, error) {
+	if cfg.Driver == DriverSQLite {
+		path := cfg.SQLitePath()
+		if path == ":memory:" {
+			return true, nil
+		}
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	sqlDB, err := openServer(cfg)
+	if err != nil {
+		return false, err
+	}
+	defer sqlDB.Close()
+
+	var query string
+	switch cfg.Driver {
+	case DriverMySQL:
+		query = "SELECT SCHEMA_NAME FROM information_schema.schemata WHERE schema_name = ?"
+	case DriverPostgres:
+		query = "SELECT datname FROM pg_database WHERE datname = $1"
+	}
+
+	var name string
+	if err := sqlDB.QueryRow(query, cfg.Name).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateDatabaseSQL returns the statement that creates the configured
+// database, or an empty string for sqlite where the file is created on open.
+func CreateDatabaseSQL(cfg config.DatabaseConfig) string {
+	switch cfg.Driver {
+	case DriverMySQL:
+		return fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;", cfg.Name)
+	case DriverPostgres:
+		// Postgres has no IF NOT EXISTS for databases; callers check first.
+		return fmt.Sprintf(`CREATE DATABASE "%s" ENCODING 'UTF8';`, cfg.Name)
+	default:
+		return ""
+	}
+}
+
+// CreateDatabase creates the configured database. For sqlite it only makes
+// sure the directory holding the database file exists.
+func CreateDatabase(cfg config.DatabaseConfig) error {
+	if cfg.Driver == DriverSQLite {
+		path := cfg.SQLitePath()
+		if path == ":memory:" {
+			return nil
+		}
+		return os.MkdirAll(filepath.Dir(path), 0755)
+	}
+
+	sqlDB, err := openServer(cfg)
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	if _, err := sqlDB.Exec(CreateDatabaseSQL(cfg)); err != nil {
+		return fmt.Errorf("create database %s: %w", cfg.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// SQLRecorder is a gorm.ConnPool used for dry runs. Reads go to the real
+// database so schema checks still see the live state; everything else is
+// recorded in order instead of being executed.
+//
+// It deliberately does not expose the underlying *sql.DB, so code that asks
+// gorm for a raw connection fails instead of silently writing.
+type SQLRecorder struct {
+	pool      gorm.ConnPool
+	dialector gorm.Dialector
+
+	mu         sync.Mutex
+	statements []string
+}
+
+// NewDryRunDB returns a session of db whose writes are captured by the
+// returned recorder.
+func NewDryRunDB(db *gorm.DB) (*gorm.DB, *SQLRecorder) {
+	recorder := &SQLRecorder{pool: db.Statement.ConnPool, dialector: db.Dialector}
+	dry := db.Session(&gorm.Session{NewDB: true})
+	dry.Statement.ConnPool = recorder
+	return dry, recorder
+}
+
+// IsDryRun reports whether db records statements instead of executing them.
+func IsDryRun(db *gorm.DB) bool {
+	switch db.Statement.ConnPool.(type) {
+	case *SQLRecorder, *recordingTx:
+		return true
+	}
+	return false
+}
+
+// Record appends a statement that was not issued through gorm, such as the
+// CREATE DATABASE run before connecting.
+func (r *SQLRecorder) Record(stmt string) {
+	stmt = strings.TrimSuffix(strings.TrimSpace(stmt), ";")
+	if stmt == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, stmt)
+}
+
+// Statements returns the recorded statements in execution order.
+func (r *SQLRecorder) Statements() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.statements...)
+}
+
+func (r *SQLRecorder) record(query string, args []interface{}) {
+	r.Record(r.dialector.Explain(query, args...))
+}
+
+// PrepareContext implements gorm.ConnPool. Prepared statements could write,
+// so they are refused.
+func (r *SQLRecorder) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported in dry-run mode")
+}
+
+// ExecContext implements gorm.ConnPool.
+func (r *SQLRecorder) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+// QueryContext implements gorm.ConnPool. Writes issued as queries, such as
+// INSERT ... RETURNING, are recorded and answered with an empty result.
+func (r *SQLRecorder) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !isReadQuery(query) {
+		r.record(query, args)
+		return r.pool.QueryContext(ctx, emptyResultSQL)
+	}
+	return r.pool.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext implements gorm.ConnPool.
+func (r *SQLRecorder) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if !isReadQuery(query) {
+		r.record(query, args)
+		return r.pool.QueryRowContext(ctx, emptyResultSQL)
+	}
+	return r.pool.QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx implements gorm.ConnPoolBeginner.
+func (r *SQLRecorder) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	r.Record("BEGIN")
+	return &recordingTx{r}, nil
+}
+
+// recordingTx is the transaction handed out by SQLRecorder. It is a separate
+// type because gorm treats any pool with Commit/Rollback as an open
+// transaction, and it has no BeginTx so gorm does not nest another one.
+type recordingTx struct {
+	r *SQLRecorder
+}
+
+func (t *recordingTx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return t.r.PrepareContext(ctx, query)
+}
+
+func (t *recordingTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.r.ExecContext(ctx, query, args...)
+}
+
+func (t *recordingTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.r.QueryContext(ctx, query, args...)
+}
+
+func (t *recordingTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return t.r.QueryRowContext(ctx, query, args...)
+}
+
+// Commit implements gorm.TxCommitter.
+func (t *recordingTx) Commit() error {
+	t.r.Record("COMMIT")
+	return nil
+}
+
+// Rollback implements gorm.TxCommitter.
+func (t *recordingTx) Rollback() error {
+	t.r.Record("ROLLBACK")
+	return nil
+}
+
+// emptyResultSQL is valid on MySQL, Postgres and SQLite and returns no rows.
+const emptyResultSQL = "SELECT NULL LIMIT 0"
+
+func isReadQuery(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "SHOW", "DESCRIBE", "EXPLAIN":
+		return true
+	case "PRAGMA":
+		return !strings.Contains(query, "=")
+	}
+	return false
+}
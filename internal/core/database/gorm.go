@@ -1,18 +1,27 @@
 package database
 
 import (
-	"reflect"
+	"fmt"
+	"slices"
 	"strings"
 	"study1/internal/core/types"
 	"unicode"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 type QueryBuilder[T any] struct {
 	DB     *gorm.DB
 	Model  T
 	Params types.QueryParams
+
+	root   *gorm.DB
+	search SearchStrategy
+	extra  []clause.Expression
+	parsed *schema.Schema
+	keyset *keysetState
 }
 
 func (b *QueryBuilder[T]) WithParams(params types.QueryParams) *QueryBuilder[T] {
@@ -20,9 +29,22 @@ func (b *QueryBuilder[T]) WithParams(params types.QueryParams) *QueryBuilder[T]
 	return b
 }
 
+// WithSearch sets how the search parameter is matched. By default the
+// strategy follows the database dialect, see SearchStrategyFor.
+func (b *QueryBuilder[T]) WithSearch(strategy SearchStrategy) *QueryBuilder[T] {
+	b.search = strategy
+	return b
+}
+
+// Where adds a condition that applies to both the page and the count, such
+// as excluding soft-deleted rows.
+func (b *QueryBuilder[T]) Where(expr clause.Expression) *QueryBuilder[T] {
+	b.extra = append(b.extra, expr)
+	return b
+}
+
 func (b *QueryBuilder[T]) Build() *gorm.DB {
-	b.applySearch()
-	b.applyFilters()
+	b.applyConditions()
 	b.applySorting()
 	b.applyPagination()
 	b.applyRelation()
@@ -30,50 +52,145 @@ func (b *QueryBuilder[T]) Build() *gorm.DB {
 	return b.DB
 }
 
-func (b *QueryBuilder[T]) applySearch() *QueryBuilder[T] {
-	if b.Params.Search == "" {
-		return b
+// Conditions returns the WHERE conditions of the query: the search, grouped
+// in parentheses, the filters and those added with Where. Build and Count
+// both use this set, so totals always match the rows listed.
+func (b *QueryBuilder[T]) Conditions() ([]clause.Expression, error) {
+	var conds []clause.Expression
+	search, err := b.searchCondition()
+	if err != nil {
+		return nil, err
 	}
+	if search != nil {
+		conds = append(conds, search)
+	}
+	filters, err := b.filterConditions()
+	if err != nil {
+		return nil, err
+	}
+	conds = append(conds, filters...)
+	return append(conds, b.extra...), nil
+}
 
-	searchableFields := b.detectSearchableFields()
+// Count counts the rows matching Conditions, ignoring sort and pagination.
+func (b *QueryBuilder[T]) Count() (int64, error) {
+	conds, err := b.Conditions()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	query := b.root.Model(&b.Model)
+	for _, cond := range conds {
+		query = query.Where(cond)
+	}
+	err = query.Count(&total).Error
+	return total, err
+}
 
-	if len(searchableFields) > 0 {
-		query := b.DB
+func (b *QueryBuilder[T]) applyConditions() *QueryBuilder[T] {
+	conds, err := b.Conditions()
+	if err != nil {
+		b.DB.AddError(err)
+		return b
+	}
+	for _, cond := range conds {
+		b.DB = b.DB.Where(cond)
+	}
+	return b
+}
 
-		for i, field := range searchableFields {
-			if i == 0 {
-				// MySQL menggunakan LIKE
-				query = query.Where(field+" LIKE ?", "%"+b.Params.Search+"%")
-			} else {
-				query = query.Or(field+" LIKE ?", "%"+b.Params.Search+"%")
-			}
-		}
+// searchCondition matches the search term in the searchable columns. The
+// result is a single expression, so alternatives cannot absorb other
+// conditions.
+func (b *QueryBuilder[T]) searchCondition() (clause.Expression, error) {
+	if b.Params.Search == "" {
+		return nil, nil
+	}
 
-		b.DB = query
+	searchableFields, err := b.detectSearchableFields()
+	if err != nil || len(searchableFields) == 0 {
+		return nil, err
 	}
+	return b.searchStrategy(searchableFields).Condition(searchableFields, b.Params.Search), nil
+}
 
-	return b
+// relevance ranks rows against the search term, or is nil without a search
+// or when the strategy cannot rank.
+func (b *QueryBuilder[T]) relevance() clause.Expression {
+	if b.Params.Search == "" {
+		return nil
+	}
+	searchableFields, err := b.detectSearchableFields()
+	if err != nil || len(searchableFields) == 0 {
+		return nil
+	}
+	return b.searchStrategy(searchableFields).Relevance(searchableFields, b.Params.Search)
 }
 
-func (b *QueryBuilder[T]) applyFilters() *QueryBuilder[T] {
-	if b.Params.Filter == nil {
-		return b
+// searchStrategy returns the strategy set with WithSearch or else the
+// dialect's. MySQL cannot MATCH columns without their FULLTEXT index, so
+// searches there fall back to LikeSearch until the index is created.
+func (b *QueryBuilder[T]) searchStrategy(columns []string) SearchStrategy {
+	if b.search != nil {
+		return b.search
 	}
+	b.search = SearchStrategyFor(b.DB.Dialector.Name())
+	if _, ok := b.search.(MySQLFullTextSearch); ok {
+		s, err := b.schema()
+		if err != nil || !hasFullTextIndex(b.root, s.Table, columns) {
+			b.search = LikeSearch{}
+		}
+	}
+	return b.search
+}
 
-	for field, value := range b.Params.Filter {
-		b.DB = b.DB.Where(field+" = ?", value)
+// filterConditions returns every filter condition; they are combined with
+// AND. Fields that are not filterable and values that do not fit the column
+// are reported as types.ErrInvalidQuery.
+func (b *QueryBuilder[T]) filterConditions() ([]clause.Expression, error) {
+	if len(b.Params.Filter) == 0 {
+		return nil, nil
 	}
 
-	return b
+	s, err := b.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	var conds []clause.Expression
+	for _, cond := range b.Params.Filter {
+		field, err := resolveField(s, TagFilterable, cond.Field, "filter by")
+		if err != nil {
+			return nil, err
+		}
+		expr, err := filterExpression(field, cond, b.DB.Dialector.Name())
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, expr)
+	}
+	return conds, nil
 }
 
+// applySorting orders by the sort parameter, e.g. "-created_at,name", or by
+// the model's DefaultSort when none is given. Searches without a sort put
+// the most relevant rows first when the search strategy can rank them.
 func (b *QueryBuilder[T]) applySorting() *QueryBuilder[T] {
-	if b.Params.Sort == "" {
-		b.DB = b.DB.Order("created_at DESC")
+	terms, err := b.sortTerms()
+	if err != nil {
+		b.DB.AddError(err)
 		return b
 	}
 
-	b.DB = b.DB.Order(b.Params.Sort)
+	var leading []clause.Expression
+	if b.Params.Sort == "" {
+		if rank := b.relevance(); rank != nil {
+			leading = append(leading, clause.Expr{SQL: "? DESC", Vars: []interface{}{rank}})
+		}
+	}
+	if len(terms) > 0 || len(leading) > 0 {
+		b.DB = b.DB.Order(orderByClause(terms, leading...))
+	}
 	return b
 }
 
@@ -95,9 +212,19 @@ func (b *QueryBuilder[T]) applyRelation() *QueryBuilder[T] {
 		return b
 	}
 
-	relations := strings.Split(b.Params.Include, ",")
-	for _, relation := range relations {
-		b.DB = b.DB.Preload(strings.TrimSpace(relation))
+	s, err := b.schema()
+	if err != nil {
+		b.DB.AddError(err)
+		return b
+	}
+
+	for _, name := range strings.Split(b.Params.Include, ",") {
+		rel, err := resolveRelation(s, strings.TrimSpace(name))
+		if err != nil {
+			b.DB.AddError(err)
+			return b
+		}
+		b.DB = b.DB.Preload(rel.Name)
 	}
 
 	return b
@@ -108,8 +235,41 @@ func (b *QueryBuilder[T]) applyFieldSelection() *QueryBuilder[T] {
 		return b
 	}
 
-	fields := strings.Split(b.Params.Fields, ",")
-	b.DB = b.DB.Select(fields)
+	s, err := b.schema()
+	if err != nil {
+		b.DB.AddError(err)
+		return b
+	}
+
+	// Fields are named as in the JSON response, which is shaped to the
+	// same names afterwards.
+	var columns []string
+	for _, name := range strings.Split(b.Params.Fields, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		field, err := resolveField(s, TagSelectable, name, "select")
+		if err == nil && fieldName(field) != name {
+			err = fmt.Errorf("%w: cannot select %q (%s)", types.ErrInvalidQuery, name, allowedList(allowedFieldNames(s, TagSelectable)))
+		}
+		if err != nil {
+			b.DB.AddError(err)
+			return b
+		}
+		if !slices.Contains(columns, field.DBName) {
+			columns = append(columns, field.DBName)
+		}
+	}
+	if b.keyset != nil {
+		// Cursors are built from the sort key, so it is always loaded.
+		for _, term := range b.keyset.terms {
+			if !slices.Contains(columns, term.Field.DBName) {
+				columns = append(columns, term.Field.DBName)
+			}
+		}
+	}
+	b.DB = b.DB.Select(columns)
 	return b
 }
 
@@ -145,38 +305,19 @@ func toSnakeCase(s string) string {
 	return b.String()
 }
 
-func (b *QueryBuilder[T]) detectSearchableFields() []string {
-	var fields []string
-
-	t := reflect.TypeOf(b.Model)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+// detectSearchableFields returns the columns of fields tagged
+// searchable:"true", including those of embedded structs.
+func (b *QueryBuilder[T]) detectSearchableFields() ([]string, error) {
+	s, err := b.schema()
+	if err != nil {
+		return nil, err
 	}
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-
-		if search := field.Tag.Get("searchable"); search == "true" {
-			// Prioritaskan gorm tag untuk column name
-			if gormTag := field.Tag.Get("gorm"); gormTag != "" {
-				// Extract column name dari gorm tag
-				parts := strings.Split(gormTag, ";")
-				for _, part := range parts {
-					if strings.HasPrefix(part, "column:") {
-						columnName := strings.TrimPrefix(part, "column:")
-						fields = append(fields, columnName)
-						break
-					}
-				}
-			} else if jsonTag := field.Tag.Get("json"); jsonTag != "" {
-				// Fallback ke json tag
-				fields = append(fields, strings.Split(jsonTag, ",")[0])
-			} else {
-				// Fallback terakhir: convert field name ke snake_case
-				fields = append(fields, toSnakeCase(field.Name))
-			}
+	var fields []string
+	for _, field := range s.Fields {
+		if field.DBName != "" && field.Tag.Get(TagSearchable) == "true" {
+			fields = append(fields, field.DBName)
 		}
 	}
-
-	return fields
+	return fields, nil
 }
@@ -0,0 +1,59 @@
+package database
+
+import "fmt"
+
+// ddlDialect holds the per-database column types and table options used by
+// the MigrationGenerator when emitting CREATE TABLE statements.
+type ddlDialect struct {
+	name        string
+	intType     string
+	bigIntType  string
+	boolType    string
+	floatType   string
+	doubleType  string
+	timeType    string
+	uuidType    string
+	tableSuffix string
+}
+
+var ddlDialects = map[string]ddlDialect{
+	DriverMySQL: {
+		name:        DriverMySQL,
+		intType:     "INT",
+		bigIntType:  "BIGINT",
+		boolType:    "TINYINT(1)",
+		floatType:   "FLOAT",
+		doubleType:  "DOUBLE",
+		timeType:    "DATETIME",
+		uuidType:    "VARCHAR(36)",
+		tableSuffix: " ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci",
+	},
+	DriverPostgres: {
+		name:       DriverPostgres,
+		intType:    "INTEGER",
+		bigIntType: "BIGINT",
+		boolType:   "BOOLEAN",
+		floatType:  "REAL",
+		doubleType: "DOUBLE PRECISION",
+		timeType:   "TIMESTAMPTZ",
+		uuidType:   "UUID",
+	},
+	DriverSQLite: {
+		name:       DriverSQLite,
+		intType:    "INTEGER",
+		bigIntType: "INTEGER",
+		boolType:   "BOOLEAN",
+		floatType:  "REAL",
+		doubleType: "REAL",
+		timeType:   "DATETIME",
+		uuidType:   "VARCHAR(36)",
+	},
+}
+
+// dialectFor returns the DDL dialect for a GORM dialector name.
+func dialectFor(name string) (ddlDialect, error) {
+	if d, ok := ddlDialects[name]; ok {
+		return d, nil
+	}
+	return ddlDialect{}, fmt.Errorf("unsupported database driver %q", name)
+}
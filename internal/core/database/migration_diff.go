@@ -0,0 +1,288 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GenerateDiff compares each model with the live schema and writes an
+// alter_<table>_table migration for every table whose columns or indexes
+// differ. Models without a table get a create migration instead, unless one
+// is already waiting to be applied.
+func (g *MigrationGenerator) GenerateDiff(models ...interface{}) error {
+	for _, model := range models {
+		tableName := getTableName(model)
+
+		if !g.db.Migrator().HasTable(tableName) {
+			if g.migrationExists(fmt.Sprintf("create_%s_table", tableName)) {
+				log.Printf("⚠️  Table %s does not exist but has a pending create migration, run `migrate up` first", tableName)
+				continue
+			}
+			if _, err := g.writeCreateMigration(model); err != nil {
+				return fmt.Errorf("failed to generate create migration for %s: %w", tableName, err)
+			}
+			log.Printf("✅ Generated create migration for new table: %s", tableName)
+			continue
+		}
+
+		upSQL, downSQL, err := g.generateDiffSQL(model)
+		if err != nil {
+			return fmt.Errorf("failed to diff table %s: %w", tableName, err)
+		}
+		if upSQL == "" {
+			log.Printf("Table %s is up to date", tableName)
+			continue
+		}
+
+		// The file is only written here; `migrate up` picks it up from the
+		// generated directory like any other migration.
+		migrationName := fmt.Sprintf("alter_%s_table", tableName)
+		version, err := g.createMigrationFile(migrationName, upSQL, downSQL)
+		if err != nil {
+			return fmt.Errorf("failed to create migration file for %s: %w", tableName, err)
+		}
+
+		log.Printf("✅ Generated diff migration for table: %s (version=%s)", tableName, version)
+	}
+
+	return nil
+}
+
+// diffColumn is the desired definition of a model column.
+type diffColumn struct {
+	name       string
+	definition string
+	sqlType    string
+	notNull    bool
+	primaryKey bool
+}
+
+// generateDiffSQL builds the ALTER statements that bring the live table in
+// line with the model, and the statements that undo them. Down statements
+// are emitted in reverse order of the up statements.
+func (g *MigrationGenerator) generateDiffSQL(model interface{}) (upSQL, downSQL string, err error) {
+	tableName := getTableName(model)
+
+	stmt := &gorm.Statement{DB: g.db}
+	if err := stmt.Parse(model); err != nil {
+		return "", "", err
+	}
+
+	liveColumns, err := g.db.Migrator().ColumnTypes(tableName)
+	if err != nil {
+		return "", "", fmt.Errorf("read columns: %w", err)
+	}
+	liveIndexes, err := g.db.Migrator().GetIndexes(tableName)
+	if err != nil {
+		return "", "", fmt.Errorf("read indexes: %w", err)
+	}
+
+	var up, down []string
+	addStep := func(upStmt, downStmt string) {
+		up = append(up, upStmt)
+		down = append([]string{downStmt}, down...)
+	}
+
+	// Desired state from the model
+	var columns []diffColumn
+	wanted := make(map[string]bool)
+	for _, field := range collectFields(reflect.TypeOf(model)) {
+		definition := g.generateColumnSQL(field, stmt)
+		if definition == "" {
+			continue
+		}
+		gormTag := field.Tag.Get("gorm")
+		name := g.getColumnName(field, stmt)
+		isPrimaryKey := strings.Contains(gormTag, "primaryKey")
+		columns = append(columns, diffColumn{
+			name:       name,
+			definition: definition,
+			sqlType:    g.mapGoTypeToSQL(field.Type, gormTag, name),
+			notNull:    strings.Contains(gormTag, "not null") || strings.Contains(gormTag, "NOT NULL") || isPrimaryKey,
+			primaryKey: isPrimaryKey,
+		})
+		wanted[strings.ToLower(name)] = true
+	}
+
+	live := make(map[string]gorm.ColumnType)
+	for _, col := range liveColumns {
+		live[strings.ToLower(col.Name())] = col
+	}
+
+	wantedIndexes := make(map[string]indexDef)
+	for _, idx := range g.modelIndexes(model, stmt) {
+		wantedIndexes[strings.ToLower(idx.Name)] = idx
+	}
+	fullText := g.fullTextIndex(model, stmt)
+
+	// Drop indexes that are no longer declared (or no longer match) first so
+	// column drops below are not blocked by them.
+	liveIndexNames := make(map[string]bool)
+	for _, idx := range liveIndexes {
+		if isPrimary, ok := idx.PrimaryKey(); (ok && isPrimary) || isInternalIndex(idx.Name()) {
+			continue
+		}
+		key := strings.ToLower(idx.Name())
+		liveIndexNames[key] = true
+
+		if fullText != nil && key == strings.ToLower(fullText.Name) {
+			continue
+		}
+		want, declared := wantedIndexes[key]
+		if declared && len(idx.Columns()) == 1 && strings.EqualFold(idx.Columns()[0], want.Column) {
+			continue
+		}
+		restore := g.createIndexSQL(tableName, idx.Name(), idx.Columns(), false)
+		if strings.HasPrefix(key, "ftx_") {
+			restore = g.createFullTextIndexSQL(tableName, fullTextIndexDef{Name: idx.Name(), Columns: idx.Columns()})
+		} else if unique, _ := idx.Unique(); unique {
+			restore = g.createIndexSQL(tableName, idx.Name(), idx.Columns(), true)
+		}
+		addStep(g.dropIndexSQL(tableName, idx.Name()), restore)
+		delete(liveIndexNames, key)
+	}
+
+	// Added and modified columns
+	for _, col := range columns {
+		liveCol, exists := live[strings.ToLower(col.name)]
+		if !exists {
+			addStep(
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", tableName, col.definition),
+				fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", tableName, col.name),
+			)
+			continue
+		}
+		if col.primaryKey || !g.columnChanged(col, liveCol) {
+			continue
+		}
+		if g.dialect.name == DriverSQLite {
+			log.Printf("⚠️  SQLite cannot modify column %s.%s in place, rebuild the table manually", tableName, col.name)
+			continue
+		}
+		upStmt, downStmt := g.modifyColumnSQL(tableName, col, liveCol)
+		addStep(upStmt, downStmt)
+	}
+
+	// Dropped columns
+	for _, liveCol := range liveColumns {
+		if wanted[strings.ToLower(liveCol.Name())] {
+			continue
+		}
+		addStep(
+			fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", tableName, liveCol.Name()),
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", tableName, liveColumnDefinition(liveCol)),
+		)
+	}
+
+	// Created indexes
+	for _, idx := range g.modelIndexes(model, stmt) {
+		if liveIndexNames[strings.ToLower(idx.Name)] {
+			continue
+		}
+		addStep(g.createIndexSQL(tableName, idx.Name, []string{idx.Column}, idx.Unique), g.dropIndexSQL(tableName, idx.Name))
+	}
+
+	// Full-text index. Postgres does not list expression indexes with the
+	// others, so it is looked up by name; stale ones are left for manual
+	// cleanup there.
+	if fullText != nil && !liveIndexNames[strings.ToLower(fullText.Name)] && !g.db.Migrator().HasIndex(tableName, fullText.Name) {
+		addStep(g.createFullTextIndexSQL(tableName, *fullText), g.dropIndexSQL(tableName, fullText.Name))
+	}
+
+	return strings.Join(up, "\n"), strings.Join(down, "\n"), nil
+}
+
+// columnChanged reports whether the live column's type or nullability
+// differs from the model. Defaults and comments are not compared.
+func (g *MigrationGenerator) columnChanged(col diffColumn, liveCol gorm.ColumnType) bool {
+	liveType, ok := liveCol.ColumnType()
+	if !ok || liveType == "" {
+		liveType = liveCol.DatabaseTypeName()
+	}
+	if normalizeSQLType(col.sqlType) != normalizeSQLType(liveType) {
+		return true
+	}
+	if nullable, ok := liveCol.Nullable(); ok && nullable == col.notNull {
+		return true
+	}
+	return false
+}
+
+// modifyColumnSQL renders the statements that change a column to the model
+// definition and back to its live definition.
+func (g *MigrationGenerator) modifyColumnSQL(tableName string, col diffColumn, liveCol gorm.ColumnType) (upSQL, downSQL string) {
+	if g.dialect.name == DriverPostgres {
+		liveType, _ := liveCol.ColumnType()
+		liveNullable, _ := liveCol.Nullable()
+		upSQL = fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s, ALTER COLUMN %s %s;",
+			tableName, col.name, col.sqlType, col.name, postgresNullClause(!col.notNull))
+		downSQL = fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s, ALTER COLUMN %s %s;",
+			tableName, col.name, liveType, col.name, postgresNullClause(liveNullable))
+		return upSQL, downSQL
+	}
+
+	upSQL = fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;", tableName, col.definition)
+	downSQL = fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;", tableName, liveColumnDefinition(liveCol))
+	return upSQL, downSQL
+}
+
+func postgresNullClause(nullable bool) string {
+	if nullable {
+		return "DROP NOT NULL"
+	}
+	return "SET NOT NULL"
+}
+
+// liveColumnDefinition rebuilds a column definition from the live schema so
+// down migrations can restore it. Defaults are not carried over.
+func liveColumnDefinition(col gorm.ColumnType) string {
+	columnType, ok := col.ColumnType()
+	if !ok || columnType == "" {
+		columnType = col.DatabaseTypeName()
+	}
+	if nullable, ok := col.Nullable(); ok && !nullable {
+		return fmt.Sprintf("%s %s NOT NULL", col.Name(), columnType)
+	}
+	return fmt.Sprintf("%s %s NULL", col.Name(), columnType)
+}
+
+// isInternalIndex skips indexes the database creates on its own.
+func isInternalIndex(name string) bool {
+	return strings.EqualFold(name, "PRIMARY") || strings.HasPrefix(name, "sqlite_autoindex_") || strings.HasSuffix(name, "_pkey")
+}
+
+var intDisplayWidth = regexp.MustCompile(`^(smallint|mediumint|int|bigint)\(\d+\)`)
+
+// sqlTypeAliases maps the spellings databases report back to the spelling
+// the generator emits, so equivalent types compare equal.
+var sqlTypeAliases = map[string]string{
+	"integer":                  "int",
+	"int4":                     "int",
+	"int8":                     "bigint",
+	"bool":                     "boolean",
+	"float4":                   "real",
+	"float8":                   "double precision",
+	"character varying":        "varchar",
+	"timestamp with time zone": "timestamptz",
+}
+
+// normalizeSQLType lower-cases a column type and folds aliases and MySQL
+// integer display widths, e.g. "INT(11)" and "integer" both become "int".
+func normalizeSQLType(sqlType string) string {
+	t := strings.ToLower(strings.TrimSpace(sqlType))
+	t = intDisplayWidth.ReplaceAllString(t, "$1")
+
+	base, args := t, ""
+	if i := strings.Index(t, "("); i >= 0 {
+		base, args = strings.TrimSpace(t[:i]), t[i:]
+	}
+	if alias, ok := sqlTypeAliases[base]; ok {
+		base = alias
+	}
+	return base + strings.ReplaceAll(args, " ", "")
+}
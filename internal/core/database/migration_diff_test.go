@@ -0,0 +1,125 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type diffTestModel struct {
+	ID        uint    `gorm:"primaryKey;autoIncrement;column:id"`
+	UUID      string  `gorm:"size:36;uniqueIndex;not null;column:uuid"`
+	Name      string  `gorm:"size:100;not null;index"`
+	Notes     string  `gorm:"type:text"`
+	Visits    int64   `gorm:"default:0"`
+	Rating    float64 `gorm:"default:-1.5"`
+	Active    bool    `gorm:"default:true"`
+	Status    string  `gorm:"size:20;default:active"`
+	CreatedAt time.Time
+}
+
+func (diffTestModel) TableName() string { return "diff_tests" }
+
+// A table created from a model's create migration must diff as up to date,
+// or every `migrate diff` would emit a spurious ALTER.
+func TestGenerateDiffAfterCreateIsEmpty(t *testing.T) {
+	db := openTestDB(t)
+	g, err := NewMigrationGenerator(db, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upSQL, _, err := g.generateTableSQL(&diffTestModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range strings.Split(upSQL, ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			if err := db.Exec(stmt).Error; err != nil {
+				t.Fatalf("%s: %v", stmt, err)
+			}
+		}
+	}
+
+	upSQL, downSQL, err := g.generateDiffSQL(&diffTestModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upSQL != "" || downSQL != "" {
+		t.Errorf("diff after create = %q / %q, want none", upSQL, downSQL)
+	}
+}
+
+func TestMapGoTypeToSQLUUID(t *testing.T) {
+	pg, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1 user=u dbname=db"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	postgresGen, err := NewMigrationGenerator(pg, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqliteGen, err := NewMigrationGenerator(openTestDB(t), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		generator *MigrationGenerator
+		fieldType reflect.Type
+		gormTag   string
+		column    string
+		want      string
+	}{
+		{"string column named uuid", postgresGen, reflect.TypeOf(""), "size:36;column:uuid", "uuid", "UUID"},
+		{"other string column", postgresGen, reflect.TypeOf(""), "size:36", "token", "VARCHAR(36)"},
+		{"type:uuid tag", postgresGen, reflect.TypeOf(""), "type:uuid", "external_id", "UUID"},
+		{"uuid.UUID field", postgresGen, reflect.TypeOf(uuid.UUID{}), "", "external_id", "UUID"},
+		{"pointer to uuid.UUID", postgresGen, reflect.TypeOf(&uuid.UUID{}), "", "external_id", "UUID"},
+		{"string column named uuid without native type", sqliteGen, reflect.TypeOf(""), "size:36;column:uuid", "uuid", "VARCHAR(36)"},
+		{"type:uuid without native type", sqliteGen, reflect.TypeOf(""), "type:uuid", "external_id", "VARCHAR(36)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.generator.mapGoTypeToSQL(tt.fieldType, tt.gormTag, tt.column); got != tt.want {
+				t.Errorf("mapGoTypeToSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractDefaultValue(t *testing.T) {
+	g, err := NewMigrationGenerator(openTestDB(t), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		gormTag string
+		want    string
+	}{
+		{"default:0", "0"},
+		{"type:int;default:-12", "-12"},
+		{"default:1.5", "1.5"},
+		{"default:true", "TRUE"},
+		{"default:False", "FALSE"},
+		{"default:CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP"},
+		{"default:active", "'active'"},
+		{"default:1e3", "'1e3'"},
+		{"default:NaN", "'NaN'"},
+		{"default:it's", "'it''s'"},
+		{"size:20", ""},
+	}
+	for _, tt := range tests {
+		if got := g.extractDefaultValue(tt.gormTag); got != tt.want {
+			t.Errorf("extractDefaultValue(%q) = %s, want %s", tt.gormTag, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package database
 
 import (
+	"bytes"
 	"fmt"
+	"go/format"
 	"log"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"text/template"
 	"time"
@@ -16,22 +19,26 @@ import (
 type MigrationGenerator struct {
 	db            *gorm.DB
 	migrationsDir string
+	dialect       ddlDialect
 }
 
-func NewMigrationGenerator(db *gorm.DB, migrationsDir string) *MigrationGenerator {
+// NewMigrationGenerator creates a generator that emits DDL for the dialect of
+// the given connection (mysql, postgres or sqlite). Other dialects are
+// rejected rather than given another database's DDL.
+func NewMigrationGenerator(db *gorm.DB, migrationsDir string) (*MigrationGenerator, error) {
+	dialect, err := dialectFor(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
 	return &MigrationGenerator{
 		db:            db,
 		migrationsDir: migrationsDir,
-	}
+		dialect:       dialect,
+	}, nil
 }
 
 // GenerateFromModels generates migration files from models
 func (g *MigrationGenerator) GenerateFromModels(models ...interface{}) error {
-	// Ensure migrations directory exists
-	if err := os.MkdirAll(g.migrationsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create migrations directory: %w", err)
-	}
-
 	for _, model := range models {
 		tableName := getTableName(model)
 		migrationName := fmt.Sprintf("create_%s_table", tableName)
@@ -42,55 +49,54 @@ func (g *MigrationGenerator) GenerateFromModels(models ...interface{}) error {
 			continue
 		}
 
-		// Generate migration SQL
-		upSQL, downSQL, err := g.generateTableSQL(model)
-		if err != nil {
-			return fmt.Errorf("failed to generate SQL for %s: %w", tableName, err)
-		}
-
 		// Create migration file and register migration in memory
-		version, err := g.createMigrationFile(migrationName, upSQL, downSQL)
+		migration, err := g.writeCreateMigration(model)
 		if err != nil {
-			return fmt.Errorf("failed to create migration file for %s: %w", tableName, err)
+			return fmt.Errorf("failed to generate migration for %s: %w", tableName, err)
 		}
 
 		// Register migration in runtime registry so it can be applied immediately
-		RegisterMigration(&Migration{
-			Version: version,
-			Name:    migrationName,
-			Up:      upSQL,
-			Down:    downSQL,
-		})
+		RegisterMigration(migration)
 
-		log.Printf("✅ Generated migration for table: %s (version=%s)", tableName, version)
+		log.Printf("✅ Generated migration for table: %s (version=%s)", tableName, migration.Version)
 	}
 
 	return nil
 }
 
-// Generate migration for a single model
+// Generate migration for a single model and register it so it can be
+// applied in the same run
 func (g *MigrationGenerator) GenerateForModel(model interface{}) error {
+	migration, err := g.writeCreateMigration(model)
+	if err != nil {
+		return err
+	}
+	RegisterMigration(migration)
+	return nil
+}
+
+// writeCreateMigration writes the create_<table>_table migration of a model.
+func (g *MigrationGenerator) writeCreateMigration(model interface{}) (*Migration, error) {
 	tableName := getTableName(model)
 	migrationName := fmt.Sprintf("create_%s_table", tableName)
 
 	// Generate migration SQL
 	upSQL, downSQL, err := g.generateTableSQL(model)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Create migration file and register
 	version, err := g.createMigrationFile(migrationName, upSQL, downSQL)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	RegisterMigration(&Migration{
+	return &Migration{
 		Version: version,
 		Name:    migrationName,
+		Dialect: g.dialect.name,
 		Up:      upSQL,
 		Down:    downSQL,
-	})
-	return nil
+	}, nil
 }
 
 // Generate SQL for creating table
@@ -106,6 +112,7 @@ func (g *MigrationGenerator) generateTableSQL(model interface{}) (upSQL, downSQL
 	// Build CREATE TABLE SQL
 	var columns []string
 	var primaryKeys []string
+	var comments []string
 
 	// Get model type and collect all exported fields, including embedded ones
 	t := reflect.TypeOf(model)
@@ -120,11 +127,18 @@ func (g *MigrationGenerator) generateTableSQL(model interface{}) (upSQL, downSQL
 			columns = append(columns, columnSQL)
 		}
 
-		// Check for primary key
-		if gormTag := field.Tag.Get("gorm"); gormTag != "" {
-			if strings.Contains(gormTag, "primaryKey") {
-				columnName := g.getColumnName(field, stmt)
-				primaryKeys = append(primaryKeys, columnName)
+		gormTag := field.Tag.Get("gorm")
+
+		// Check for primary key. SQLite declares auto-increment keys inline.
+		if strings.Contains(gormTag, "primaryKey") && !g.inlinePrimaryKey(gormTag) {
+			primaryKeys = append(primaryKeys, g.getColumnName(field, stmt))
+		}
+
+		// Postgres has no inline column comments
+		if g.dialect.name == DriverPostgres && columnSQL != "" {
+			if comment := g.extractComment(gormTag); comment != "" {
+				comments = append(comments, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;",
+					tableName, g.getColumnName(field, stmt), g.quoteString(comment)))
 			}
 		}
 	}
@@ -136,7 +150,7 @@ func (g *MigrationGenerator) generateTableSQL(model interface{}) (upSQL, downSQL
 		createSQL += fmt.Sprintf(",\n  PRIMARY KEY (%s)", strings.Join(primaryKeys, ", "))
 	}
 
-	createSQL += "\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;"
+	createSQL += "\n)" + g.dialect.tableSuffix + ";"
 
 	// Add indexes
 	indexes := g.generateIndexSQL(model, stmt)
@@ -144,6 +158,10 @@ func (g *MigrationGenerator) generateTableSQL(model interface{}) (upSQL, downSQL
 		createSQL += "\n\n" + indexes
 	}
 
+	if len(comments) > 0 {
+		createSQL += "\n\n" + strings.Join(comments, "\n")
+	}
+
 	upSQL = createSQL
 	downSQL = fmt.Sprintf("DROP TABLE IF EXISTS %s;", tableName)
 
@@ -165,19 +183,24 @@ func (g *MigrationGenerator) generateColumnSQL(field reflect.StructField, stmt *
 		return ""
 	}
 
+	// Handle primary key
+	isPrimaryKey := strings.Contains(gormTag, "primaryKey")
+	isAutoIncrement := strings.Contains(gormTag, "autoIncrement") || strings.Contains(gormTag, "AUTO_INCREMENT")
+
 	// Determine SQL type
-	sqlType := g.mapGoTypeToSQL(fieldType, gormTag)
+	sqlType := g.mapGoTypeToSQL(fieldType, gormTag, columnName)
 	if sqlType == "" {
 		return ""
 	}
+	if isAutoIncrement && g.dialect.name == DriverSQLite {
+		// Only INTEGER PRIMARY KEY columns alias the rowid in SQLite
+		sqlType = "INTEGER"
+	}
 
 	// Build column definition
 	var parts []string
 	parts = append(parts, fmt.Sprintf("%s %s", columnName, sqlType))
 
-	// Handle primary key
-	isPrimaryKey := strings.Contains(gormTag, "primaryKey")
-
 	// Add NOT NULL
 	if strings.Contains(gormTag, "not null") || strings.Contains(gormTag, "NOT NULL") || isPrimaryKey {
 		parts = append(parts, "NOT NULL")
@@ -185,9 +208,18 @@ func (g *MigrationGenerator) generateColumnSQL(field reflect.StructField, stmt *
 		parts = append(parts, "NULL")
 	}
 
-	// Add AUTO_INCREMENT (harus setelah NOT NULL)
-	if strings.Contains(gormTag, "autoIncrement") || strings.Contains(gormTag, "AUTO_INCREMENT") {
-		parts = append(parts, "AUTO_INCREMENT")
+	// Add auto increment (harus setelah NOT NULL)
+	if isAutoIncrement {
+		switch g.dialect.name {
+		case DriverPostgres:
+			parts = append(parts, "GENERATED BY DEFAULT AS IDENTITY")
+		case DriverSQLite:
+			if isPrimaryKey {
+				parts = append(parts, "PRIMARY KEY AUTOINCREMENT")
+			}
+		default:
+			parts = append(parts, "AUTO_INCREMENT")
+		}
 	}
 
 	// Add DEFAULT value
@@ -195,14 +227,21 @@ func (g *MigrationGenerator) generateColumnSQL(field reflect.StructField, stmt *
 		parts = append(parts, fmt.Sprintf("DEFAULT %s", defaultVal))
 	}
 
-	// Add COMMENT
-	if comment := g.extractComment(gormTag); comment != "" {
-		parts = append(parts, fmt.Sprintf("COMMENT '%s'", comment))
+	// Add COMMENT (MySQL only; Postgres uses COMMENT ON after the table)
+	if comment := g.extractComment(gormTag); comment != "" && g.dialect.name == DriverMySQL {
+		parts = append(parts, "COMMENT "+g.quoteString(comment))
 	}
 
 	return strings.Join(parts, " ")
 }
 
+// inlinePrimaryKey reports whether the primary key is declared on the column
+// itself rather than in a table-level PRIMARY KEY clause.
+func (g *MigrationGenerator) inlinePrimaryKey(gormTag string) bool {
+	isAutoIncrement := strings.Contains(gormTag, "autoIncrement") || strings.Contains(gormTag, "AUTO_INCREMENT")
+	return g.dialect.name == DriverSQLite && isAutoIncrement
+}
+
 // Get column name from field
 func (g *MigrationGenerator) getColumnName(field reflect.StructField, stmt *gorm.Statement) string {
 	gormTag := field.Tag.Get("gorm")
@@ -226,8 +265,13 @@ func (g *MigrationGenerator) getColumnName(field reflect.StructField, stmt *gorm
 	return toSnakeCase(name)
 }
 
-// Map Go type to SQL type
-func (g *MigrationGenerator) mapGoTypeToSQL(fieldType reflect.Type, gormTag string) string {
+// Map Go type to SQL type for the generator's dialect
+func (g *MigrationGenerator) mapGoTypeToSQL(fieldType reflect.Type, gormTag, columnName string) string {
+	// UUIDs get a native type where the dialect has one
+	if isUUIDField(fieldType, gormTag, columnName) {
+		return g.dialect.uuidType
+	}
+
 	// Check if type is specified in gorm tag
 	if strings.Contains(gormTag, "type:") {
 		parts := strings.Split(gormTag, ";")
@@ -267,29 +311,29 @@ func (g *MigrationGenerator) mapGoTypeToSQL(fieldType reflect.Type, gormTag stri
 		return fmt.Sprintf("VARCHAR(%s)", size)
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
-		return "INT"
+		return g.dialect.intType
 
 	case reflect.Int64, reflect.Uint64:
-		return "BIGINT"
+		return g.dialect.bigIntType
 
 	case reflect.Bool:
-		return "TINYINT(1)"
+		return g.dialect.boolType
 
 	case reflect.Float32:
-		return "FLOAT"
+		return g.dialect.floatType
 
 	case reflect.Float64:
-		return "DOUBLE"
+		return g.dialect.doubleType
 
 	case reflect.Struct:
-		// time.Time -> DATETIME
+		// time.Time -> DATETIME / TIMESTAMPTZ
 		if fieldType.String() == "time.Time" {
-			return "DATETIME"
+			return g.dialect.timeType
 		}
-		// gorm.DeletedAt type should be mapped to DATETIME so it can be indexed
+		// gorm.DeletedAt type should be mapped to a timestamp so it can be indexed
 		// (gorm.DeletedAt is a struct type in the gorm package)
 		if strings.HasSuffix(fieldType.String(), ".DeletedAt") || fieldType.String() == "gorm.DeletedAt" {
-			return "DATETIME"
+			return g.dialect.timeType
 		}
 	case reflect.Ptr:
 		// Handle pointer types: map common pointer-to-primitive types to SQL
@@ -297,12 +341,14 @@ func (g *MigrationGenerator) mapGoTypeToSQL(fieldType reflect.Type, gormTag stri
 		elem := fieldType.Elem()
 		switch elem.Kind() {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
-			return "INT"
+			return g.dialect.intType
 		case reflect.Int64, reflect.Uint64:
-			return "BIGINT"
+			return g.dialect.bigIntType
+		case reflect.Bool:
+			return g.dialect.boolType
 		case reflect.Struct:
 			if elem.String() == "time.Time" {
-				return "DATETIME"
+				return g.dialect.timeType
 			}
 		}
 	}
@@ -310,6 +356,27 @@ func (g *MigrationGenerator) mapGoTypeToSQL(fieldType reflect.Type, gormTag stri
 	return "TEXT"
 }
 
+// isUUIDField reports whether a field holds a UUID: its type is named UUID,
+// like uuid.UUID, its gorm tag says type:uuid, or it is a string column
+// named uuid, like types.BaseModel.UUID.
+func isUUIDField(fieldType reflect.Type, gormTag, columnName string) bool {
+	for _, part := range strings.Split(gormTag, ";") {
+		if strings.EqualFold(strings.TrimSpace(part), "type:uuid") {
+			return true
+		}
+	}
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() == reflect.String && strings.EqualFold(columnName, "uuid") {
+		return true
+	}
+	return fieldType.Name() == "UUID"
+}
+
+// numericDefault matches default values emitted without quotes.
+var numericDefault = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
 // Extract default value from gorm tag
 func (g *MigrationGenerator) extractDefaultValue(gormTag string) string {
 	if strings.Contains(gormTag, "default:") {
@@ -321,7 +388,15 @@ func (g *MigrationGenerator) extractDefaultValue(gormTag string) string {
 				if value == "CURRENT_TIMESTAMP" {
 					return value
 				}
-				return "'" + value + "'"
+				// Numbers and booleans are emitted as literals, the way
+				// databases report them back
+				if numericDefault.MatchString(value) {
+					return value
+				}
+				if strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+					return strings.ToUpper(value)
+				}
+				return g.quoteString(value)
 			}
 		}
 	}
@@ -341,18 +416,44 @@ func (g *MigrationGenerator) extractComment(gormTag string) string {
 	return ""
 }
 
+// quoteString renders s as a SQL string literal. Quotes are doubled on
+// every dialect; MySQL also treats backslashes as escapes by default.
+func (g *MigrationGenerator) quoteString(s string) string {
+	if g.dialect.name == DriverMySQL {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// indexDef describes a single-column index declared on a model field.
+type indexDef struct {
+	Name   string
+	Column string
+	Unique bool
+}
+
 // Generate index SQL
 func (g *MigrationGenerator) generateIndexSQL(model interface{}, stmt *gorm.Statement) string {
 	var indexes []string
-	t := reflect.TypeOf(model)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
+	tableName := getTableName(model)
+
+	for _, idx := range g.modelIndexes(model, stmt) {
+		indexes = append(indexes, g.createIndexSQL(tableName, idx.Name, []string{idx.Column}, idx.Unique))
+	}
+	if ftx := g.fullTextIndex(model, stmt); ftx != nil {
+		indexes = append(indexes, g.createFullTextIndexSQL(tableName, *ftx))
 	}
 
+	return strings.Join(indexes, "\n")
+}
+
+// modelIndexes returns the indexes declared through gorm index/uniqueIndex
+// tags, including those on embedded structs such as types.BaseModel.
+func (g *MigrationGenerator) modelIndexes(model interface{}, stmt *gorm.Statement) []indexDef {
+	var indexes []indexDef
 	tableName := getTableName(model)
 
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
+	for _, field := range collectFields(reflect.TypeOf(model)) {
 		gormTag := field.Tag.Get("gorm")
 		columnName := g.getColumnName(field, stmt)
 
@@ -369,8 +470,7 @@ func (g *MigrationGenerator) generateIndexSQL(model interface{}, stmt *gorm.Stat
 			if indexName == "" {
 				indexName = fmt.Sprintf("uidx_%s_%s", tableName, columnName)
 			}
-			indexes = append(indexes, fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s);",
-				indexName, tableName, columnName))
+			indexes = append(indexes, indexDef{Name: indexName, Column: columnName, Unique: true})
 
 		} else if strings.Contains(gormTag, "index") {
 			// Extract index name from gorm tag
@@ -385,26 +485,85 @@ func (g *MigrationGenerator) generateIndexSQL(model interface{}, stmt *gorm.Stat
 			if indexName == "" {
 				indexName = fmt.Sprintf("idx_%s_%s", tableName, columnName)
 			}
-			indexes = append(indexes, fmt.Sprintf("CREATE INDEX %s ON %s (%s);",
-				indexName, tableName, columnName))
+			indexes = append(indexes, indexDef{Name: indexName, Column: columnName})
 		}
 	}
 
-	return strings.Join(indexes, "\n")
+	return indexes
+}
+
+// fullTextIndexDef is the full-text index over a model's searchable columns.
+type fullTextIndexDef struct {
+	Name    string
+	Columns []string
 }
 
-// Check if migration already exists
+// fullTextIndex returns the index that the dialect's SearchStrategy searches
+// through, over the fields tagged searchable:"true", or nil when the model
+// has none or the dialect has no full-text index (sqlite).
+func (g *MigrationGenerator) fullTextIndex(model interface{}, stmt *gorm.Statement) *fullTextIndexDef {
+	if g.dialect.name == DriverSQLite {
+		return nil
+	}
+
+	var columns []string
+	for _, field := range collectFields(reflect.TypeOf(model)) {
+		if field.Tag.Get(TagSearchable) == "true" {
+			columns = append(columns, g.getColumnName(field, stmt))
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	return &fullTextIndexDef{
+		Name:    fullTextIndexName(getTableName(model), columns),
+		Columns: columns,
+	}
+}
+
+// createFullTextIndexSQL renders a FULLTEXT index for MySQL and a GIN index
+// on the search tsvector for Postgres.
+func (g *MigrationGenerator) createFullTextIndexSQL(tableName string, idx fullTextIndexDef) string {
+	if g.dialect.name == DriverPostgres {
+		return fmt.Sprintf("CREATE INDEX %s ON %s USING GIN (%s);", idx.Name, tableName, tsvectorSQL(searchConfig, idx.Columns))
+	}
+	return fmt.Sprintf("CREATE FULLTEXT INDEX %s ON %s (%s);", idx.Name, tableName, strings.Join(idx.Columns, ", "))
+}
+
+// createIndexSQL renders a CREATE [UNIQUE] INDEX statement.
+func (g *MigrationGenerator) createIndexSQL(tableName, indexName string, columns []string, unique bool) string {
+	if unique {
+		return fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s);", indexName, tableName, strings.Join(columns, ", "))
+	}
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s);", indexName, tableName, strings.Join(columns, ", "))
+}
+
+// dropIndexSQL renders a DROP INDEX statement; MySQL scopes index names to
+// their table while Postgres and SQLite do not.
+func (g *MigrationGenerator) dropIndexSQL(tableName, indexName string) string {
+	if g.dialect.name == DriverMySQL {
+		return fmt.Sprintf("DROP INDEX %s ON %s;", indexName, tableName)
+	}
+	return fmt.Sprintf("DROP INDEX %s;", indexName)
+}
+
+// Check if migration already exists for the generator's dialect, or for
+// every dialect
 func (g *MigrationGenerator) migrationExists(migrationName string) bool {
-	pattern := filepath.Join(g.migrationsDir, "*_"+migrationName+".go")
-	matches, _ := filepath.Glob(pattern)
-	return len(matches) > 0
+	for _, suffix := range []string{".go", "." + g.dialect.name + ".go"} {
+		pattern := filepath.Join(g.migrationsDir, "*_"+migrationName+suffix)
+		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
 }
 
-// Create migration file
+// Create migration file. A dry run prints the files instead of writing
+// them, leaving the migrations directory untouched.
 func (g *MigrationGenerator) createMigrationFile(migrationName, upSQL, downSQL string) (string, error) {
 	version := time.Now().Format("20060102150405")
-	filename := fmt.Sprintf("%s_%s.go", version, migrationName)
-	filePath := filepath.Join(g.migrationsDir, filename)
+	base := fmt.Sprintf("%s_%s.%s", version, migrationName, g.dialect.name)
 
 	// Create migration file template
 	tmpl := `package migrations
@@ -417,6 +576,7 @@ func init() {
 	database.RegisterMigration(&database.Migration{
 		Version: "{{.Version}}",
 		Name:    "{{.Name}}",
+		Dialect: "{{.Dialect}}",
 		Up: ` + "`" + `{{.UpSQL}}` + "`" + `,
 		Down: ` + "`" + `{{.DownSQL}}` + "`" + `,
 	})
@@ -426,36 +586,53 @@ func init() {
 	data := struct {
 		Version string
 		Name    string
+		Dialect string
 		UpSQL   string
 		DownSQL string
 	}{
 		Version: version,
 		Name:    migrationName,
+		Dialect: g.dialect.name,
 		UpSQL:   upSQL,
 		DownSQL: downSQL,
 	}
 
-	file, err := os.Create(filePath)
-	if err != nil {
+	var buf bytes.Buffer
+	template := template.Must(template.New("migration").Parse(tmpl))
+	if err := template.Execute(&buf, data); err != nil {
 		return "", err
 	}
-	defer file.Close()
-
-	template := template.Must(template.New("migration").Parse(tmpl))
-	if err := template.Execute(file, data); err != nil {
+	goFile, err := format.Source(buf.Bytes())
+	if err != nil {
 		return "", err
 	}
 
 	// Also write plain SQL files so runtime can apply migrations even if
 	// generated Go files are not compiled into the running binary.
-	upPath := filepath.Join(g.migrationsDir, fmt.Sprintf("%s_%s.up.sql", version, migrationName))
-	downPath := filepath.Join(g.migrationsDir, fmt.Sprintf("%s_%s.down.sql", version, migrationName))
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{base + ".go", goFile},
+		{base + ".up.sql", []byte(upSQL)},
+		{base + ".down.sql", []byte(downSQL)},
+	}
 
-	if err := os.WriteFile(upPath, []byte(upSQL), 0644); err != nil {
-		return "", err
+	if IsDryRun(g.db) {
+		for _, file := range files {
+			fmt.Printf("-- Dry run: would write %s\n%s\n\n", filepath.Join(g.migrationsDir, file.name), file.content)
+		}
+		return version, nil
 	}
-	if err := os.WriteFile(downPath, []byte(downSQL), 0644); err != nil {
-		return "", err
+
+	// Ensure migrations directory exists
+	if err := os.MkdirAll(g.migrationsDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(g.migrationsDir, file.name), file.content, 0644); err != nil {
+			return "", err
+		}
 	}
 
 	return version, nil
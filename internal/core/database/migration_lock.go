@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	// migrationLockName is the MySQL named lock held while migrations run.
+	migrationLockName = "study1_migrations"
+	// migrationLockKey is the Postgres advisory lock key.
+	migrationLockKey = 720951334
+	// migrationLockTimeout bounds how long a run waits for another process.
+	migrationLockTimeout = 60 * time.Second
+)
+
+// ErrMigrationLocked is returned when another process holds the migration lock.
+var ErrMigrationLocked = errors.New("another migration run holds the lock")
+
+// AcquireMigrationLock takes a cross-process lock so only one process runs
+// migrations at a time. MySQL and Postgres use session-level advisory locks on
+// a dedicated connection; SQLite uses a lock file next to the database. The
+// returned function releases the lock. Dry runs write nothing and take no lock.
+func AcquireMigrationLock(db *gorm.DB) (func() error, error) {
+	if IsDryRun(db) {
+		return func() error { return nil }, nil
+	}
+
+	switch db.Dialector.Name() {
+	case DriverMySQL:
+		return acquireSessionLock(db,
+			"SELECT GET_LOCK(?, ?)", []interface{}{migrationLockName, int(migrationLockTimeout.Seconds())},
+			"SELECT RELEASE_LOCK(?)", []interface{}{migrationLockName},
+			false)
+	case DriverPostgres:
+		return acquireSessionLock(db,
+			"SELECT pg_try_advisory_lock($1)", []interface{}{migrationLockKey},
+			"SELECT pg_advisory_unlock($1)", []interface{}{migrationLockKey},
+			true)
+	case DriverSQLite:
+		return acquireFileLock(db)
+	default:
+		return func() error { return nil }, nil
+	}
+}
+
+// acquireSessionLock holds a connection out of the pool for the lifetime of
+// the lock because advisory locks belong to the session that took them.
+// When poll is set the lock query is non-blocking and is retried until the
+// timeout; otherwise the query itself waits.
+func acquireSessionLock(db *gorm.DB, lockSQL string, lockArgs []interface{}, unlockSQL string, unlockArgs []interface{}, poll bool) (func() error, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(migrationLockTimeout)
+	for {
+		var acquired sql.NullBool
+		if err := conn.QueryRowContext(ctx, lockSQL, lockArgs...).Scan(&acquired); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if acquired.Valid && acquired.Bool {
+			break
+		}
+		if !poll || time.Now().After(deadline) {
+			conn.Close()
+			return nil, ErrMigrationLocked
+		}
+		log.Println("Waiting for migration lock held by another process...")
+		time.Sleep(2 * time.Second)
+	}
+
+	return func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(ctx, unlockSQL, unlockArgs...)
+		return err
+	}, nil
+}
+
+// acquireFileLock creates <database>.migrate.lock exclusively. A stale file
+// left by a crashed run must be removed by hand.
+func acquireFileLock(db *gorm.DB) (func() error, error) {
+	dialector, ok := db.Dialector.(*sqlite.Dialector)
+	if !ok || dialector.DSN == "" || dialector.DSN == ":memory:" {
+		return func() error { return nil }, nil
+	}
+
+	path := dialector.DSN + ".migrate.lock"
+	deadline := time.Now().Add(migrationLockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w (remove %s if no migration is running)", ErrMigrationLocked, path)
+		}
+		log.Println("Waiting for migration lock held by another process...")
+		time.Sleep(2 * time.Second)
+	}
+
+	return func() error {
+		return os.Remove(path)
+	}, nil
+}
+
+// SupportsTransactionalDDL reports whether schema changes can be rolled back
+// inside a transaction. MySQL commits implicitly on DDL statements.
+func SupportsTransactionalDDL(db *gorm.DB) bool {
+	return db.Dialector.Name() != DriverMySQL
+}
+
+// Checksum returns the hex SHA-256 of a migration's SQL, stored on its
+// MigrationRecord to detect edits after it was applied. Leading and trailing
+// whitespace is ignored, as MergeSources ignores it when the same migration
+// comes from several sources, so the checksum does not depend on which
+// source was read first.
+func Checksum(sqlText string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(sqlText)))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,9 @@
 package database
 
-import "sort"
+import (
+	"sort"
+	"strings"
+)
 
 // Migration represents a database migration
 type Migration struct {
@@ -8,6 +11,16 @@ type Migration struct {
 	Name    string
 	Up      string
 	Down    string
+	// Dialect restricts the migration to one database driver (mysql,
+	// postgres or sqlite). Migrations without one run on every database.
+	Dialect string
+	// Source names the MigrationSource the migration was loaded from.
+	Source string
+}
+
+// AppliesTo reports whether the migration runs on the given dialect.
+func (m *Migration) AppliesTo(dialect string) bool {
+	return m.Dialect == "" || dialect == "" || m.Dialect == dialect
 }
 
 var migrations []*Migration
@@ -34,3 +47,34 @@ func GetMigrationByVersion(version string) *Migration {
 	}
 	return nil
 }
+
+// GetMigrationByVersionName returns a migration by version and name. Several
+// migrations can share a version when generated in the same second.
+func GetMigrationByVersionName(version, name string) *Migration {
+	for _, migration := range migrations {
+		if migration.Version == version && migration.Name == name {
+			return migration
+		}
+	}
+	return nil
+}
+
+// MigrationsOf returns the registered migrations for dialect of the tables a
+// module owns, matched by the generated create_<table>_table and
+// alter_<table>_table names.
+func MigrationsOf(module, dialect string) []*Migration {
+	var out []*Migration
+	for _, migration := range GetMigrations() {
+		if !migration.AppliesTo(dialect) {
+			continue
+		}
+		for _, prefix := range []string{"create_", "alter_"} {
+			table := strings.TrimSuffix(strings.TrimPrefix(migration.Name, prefix), "_table")
+			if strings.HasPrefix(migration.Name, prefix) && TableOwner(table) == module {
+				out = append(out, migration)
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+// MigrationSource provides migrations from one origin, e.g. the in-memory
+// registry, SQL files embedded in the binary or a directory on disk.
+type MigrationSource interface {
+	// Name identifies the source in logs and conflict errors.
+	Name() string
+	// Migrations returns the migrations the source knows about.
+	Migrations() ([]*Migration, error)
+}
+
+// RegistrySource serves migrations added through RegisterMigration that
+// apply to Dialect.
+type RegistrySource struct {
+	Dialect string
+}
+
+// Name implements MigrationSource.
+func (RegistrySource) Name() string { return "registry" }
+
+// Migrations implements MigrationSource.
+func (s RegistrySource) Migrations() ([]*Migration, error) {
+	var out []*Migration
+	for _, m := range GetMigrations() {
+		if !m.AppliesTo(s.Dialect) {
+			continue
+		}
+		copied := *m
+		copied.Source = s.Name()
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+// FSSource serves <version>_<name>.up.sql / .down.sql pairs from a file
+// system, such as an embed.FS of the generated migrations directory. Files
+// tagged with a dialect, <version>_<name>.<dialect>.up.sql, are only served
+// when it matches Dialect.
+type FSSource struct {
+	Label   string
+	FS      fs.FS
+	Dialect string
+}
+
+// NewDirSource returns a source reading SQL files for dialect from dir. A
+// missing directory yields no migrations, so binaries run outside the repo
+// still work.
+func NewDirSource(dir, dialect string) *FSSource {
+	return &FSSource{Label: dir, FS: os.DirFS(dir), Dialect: dialect}
+}
+
+// Name implements MigrationSource.
+func (s *FSSource) Name() string { return s.Label }
+
+// Migrations implements MigrationSource.
+func (s *FSSource) Migrations() ([]*Migration, error) {
+	matches, err := fs.Glob(s.FS, "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*Migration
+	for _, upFile := range matches {
+		base := strings.TrimSuffix(upFile, ".up.sql")
+		name, dialect := splitDialect(base)
+		parts := strings.SplitN(name, "_", 2)
+		if len(parts) < 2 {
+			continue
+		}
+		mig := &Migration{Version: parts[0], Name: parts[1], Dialect: dialect, Source: s.Name()}
+		if !mig.AppliesTo(s.Dialect) {
+			continue
+		}
+
+		up, err := fs.ReadFile(s.FS, upFile)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		down, err := fs.ReadFile(s.FS, base+".down.sql")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		mig.Up, mig.Down = string(up), string(down)
+		out = append(out, mig)
+	}
+	return out, nil
+}
+
+// splitDialect splits the dialect tag off a migration file's base name, as
+// in 20251118152613_create_users_table.mysql.
+func splitDialect(base string) (name, dialect string) {
+	if i := strings.LastIndex(base, "."); i >= 0 {
+		if _, ok := ddlDialects[base[i+1:]]; ok {
+			return base[:i], base[i+1:]
+		}
+	}
+	return base, ""
+}
+
+// MergeSources loads every source and merges migrations by version and
+// name. The same migration may come from several sources as long as its Up
+// SQL is identical; otherwise a conflict error is returned. The result is
+// ordered by version, then name.
+func MergeSources(sources ...MigrationSource) ([]*Migration, error) {
+	merged := make(map[string]*Migration)
+	var conflicts []string
+
+	for _, source := range sources {
+		migs, err := source.Migrations()
+		if err != nil {
+			return nil, fmt.Errorf("load migrations from %s: %w", source.Name(), err)
+		}
+		for _, mig := range migs {
+			key := mig.Version + "_" + mig.Name
+			existing, ok := merged[key]
+			if !ok {
+				merged[key] = mig
+				continue
+			}
+			if strings.TrimSpace(existing.Up) != strings.TrimSpace(mig.Up) {
+				conflicts = append(conflicts, fmt.Sprintf("%s (%s vs %s)", key, existing.Source, mig.Source))
+				continue
+			}
+			if existing.Down == "" {
+				existing.Down = mig.Down
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("conflicting migrations: %s", strings.Join(conflicts, ", "))
+	}
+
+	out := make([]*Migration, 0, len(merged))
+	for _, mig := range merged {
+		out = append(out, mig)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Version != out[j].Version {
+			return out[i].Version < out[j].Version
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
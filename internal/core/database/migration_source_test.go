@@ -0,0 +1,44 @@
+package database
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSSourceFiltersByDialect(t *testing.T) {
+	files := fstest.MapFS{
+		"20250101000000_create_things_table.mysql.up.sql":      {Data: []byte("CREATE TABLE things (id INT AUTO_INCREMENT)")},
+		"20250101000000_create_things_table.mysql.down.sql":    {Data: []byte("DROP TABLE things")},
+		"20250101000000_create_things_table.postgres.up.sql":   {Data: []byte("CREATE TABLE things (id INTEGER GENERATED BY DEFAULT AS IDENTITY)")},
+		"20250101000000_create_things_table.postgres.down.sql": {Data: []byte("DROP TABLE things")},
+		"20250102000000_seed_things.up.sql":                    {Data: []byte("INSERT INTO things DEFAULT VALUES")},
+	}
+
+	tests := []struct {
+		dialect string
+		want    []string
+	}{
+		{DriverMySQL, []string{"20250101000000_create_things_table/mysql", "20250102000000_seed_things/"}},
+		{DriverPostgres, []string{"20250101000000_create_things_table/postgres", "20250102000000_seed_things/"}},
+		{DriverSQLite, []string{"20250102000000_seed_things/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			migs, err := MergeSources(&FSSource{Label: "test", FS: files, Dialect: tt.dialect})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, mig := range migs {
+				got = append(got, mig.Version+"_"+mig.Name+"/"+mig.Dialect)
+				if mig.Dialect != "" && mig.Down == "" {
+					t.Errorf("%s: down SQL not loaded", mig.Name)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("migrations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ func init() {
 	database.RegisterMigration(&database.Migration{
 		Version: "20251118152613",
 		Name:    "create_activity_logs_table",
+		Dialect: "mysql",
 		Up: `CREATE TABLE IF NOT EXISTS activity_logs (
   id INT NOT NULL AUTO_INCREMENT,
   uuid VARCHAR(36) NOT NULL,
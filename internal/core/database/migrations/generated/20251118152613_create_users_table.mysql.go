@@ -8,6 +8,7 @@ func init() {
 	database.RegisterMigration(&database.Migration{
 		Version: "20251118152613",
 		Name:    "create_users_table",
+		Dialect: "mysql",
 		Up: `CREATE TABLE IF NOT EXISTS users (
   id INT NOT NULL AUTO_INCREMENT,
   uuid VARCHAR(36) NOT NULL,
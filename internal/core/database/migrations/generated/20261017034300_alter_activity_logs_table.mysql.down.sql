@@ -0,0 +1,9 @@
+DROP INDEX idx_activity_logs_request_id ON activity_logs;
+DROP INDEX uidx_activity_logs_uuid ON activity_logs;
+ALTER TABLE activity_logs DROP COLUMN response_body;
+ALTER TABLE activity_logs DROP COLUMN request_body;
+ALTER TABLE activity_logs DROP COLUMN errors;
+ALTER TABLE activity_logs DROP COLUMN response_size;
+ALTER TABLE activity_logs DROP COLUMN query;
+ALTER TABLE activity_logs DROP COLUMN raw_path;
+ALTER TABLE activity_logs DROP COLUMN request_id;
\ No newline at end of file
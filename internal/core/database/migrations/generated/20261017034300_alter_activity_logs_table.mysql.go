@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"study1/internal/core/database"
+)
+
+func init() {
+	database.RegisterMigration(&database.Migration{
+		Version: "20261017034300",
+		Name:    "alter_activity_logs_table",
+		Dialect: "mysql",
+		Up: `ALTER TABLE activity_logs ADD COLUMN request_id VARCHAR(64) NULL;
+ALTER TABLE activity_logs ADD COLUMN raw_path VARCHAR(2048) NULL;
+ALTER TABLE activity_logs ADD COLUMN query VARCHAR(2048) NULL;
+ALTER TABLE activity_logs ADD COLUMN response_size INT NULL;
+ALTER TABLE activity_logs ADD COLUMN errors text NULL;
+ALTER TABLE activity_logs ADD COLUMN request_body text NULL;
+ALTER TABLE activity_logs ADD COLUMN response_body text NULL;
+CREATE UNIQUE INDEX uidx_activity_logs_uuid ON activity_logs (uuid);
+CREATE INDEX idx_activity_logs_request_id ON activity_logs (request_id);`,
+		Down: `DROP INDEX idx_activity_logs_request_id ON activity_logs;
+DROP INDEX uidx_activity_logs_uuid ON activity_logs;
+ALTER TABLE activity_logs DROP COLUMN response_body;
+ALTER TABLE activity_logs DROP COLUMN request_body;
+ALTER TABLE activity_logs DROP COLUMN errors;
+ALTER TABLE activity_logs DROP COLUMN response_size;
+ALTER TABLE activity_logs DROP COLUMN query;
+ALTER TABLE activity_logs DROP COLUMN raw_path;
+ALTER TABLE activity_logs DROP COLUMN request_id;`,
+	})
+}
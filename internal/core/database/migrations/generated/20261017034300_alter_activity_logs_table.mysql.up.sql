@@ -0,0 +1,9 @@
+ALTER TABLE activity_logs ADD COLUMN request_id VARCHAR(64) NULL;
+ALTER TABLE activity_logs ADD COLUMN raw_path VARCHAR(2048) NULL;
+ALTER TABLE activity_logs ADD COLUMN query VARCHAR(2048) NULL;
+ALTER TABLE activity_logs ADD COLUMN response_size INT NULL;
+ALTER TABLE activity_logs ADD COLUMN errors text NULL;
+ALTER TABLE activity_logs ADD COLUMN request_body text NULL;
+ALTER TABLE activity_logs ADD COLUMN response_body text NULL;
+CREATE UNIQUE INDEX uidx_activity_logs_uuid ON activity_logs (uuid);
+CREATE INDEX idx_activity_logs_request_id ON activity_logs (request_id);
\ No newline at end of file
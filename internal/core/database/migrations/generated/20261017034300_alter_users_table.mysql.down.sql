@@ -0,0 +1,2 @@
+DROP INDEX idx_users_deleted_at ON users;
+DROP INDEX uidx_users_uuid ON users;
\ No newline at end of file
@@ -0,0 +1,17 @@
+package migrations
+
+import (
+	"study1/internal/core/database"
+)
+
+func init() {
+	database.RegisterMigration(&database.Migration{
+		Version: "20261017034300",
+		Name:    "alter_users_table",
+		Dialect: "mysql",
+		Up: `CREATE UNIQUE INDEX uidx_users_uuid ON users (uuid);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);`,
+		Down: `DROP INDEX idx_users_deleted_at ON users;
+DROP INDEX uidx_users_uuid ON users;`,
+	})
+}
@@ -0,0 +1,2 @@
+CREATE UNIQUE INDEX uidx_users_uuid ON users (uuid);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX idx_activity_logs_created_at ON activity_logs;
\ No newline at end of file
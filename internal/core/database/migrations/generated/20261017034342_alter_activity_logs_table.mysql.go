@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"study1/internal/core/database"
+)
+
+func init() {
+	database.RegisterMigration(&database.Migration{
+		Version: "20261017034342",
+		Name:    "alter_activity_logs_table",
+		Dialect: "mysql",
+		Up:      `CREATE INDEX idx_activity_logs_created_at ON activity_logs (created_at);`,
+		Down:    `DROP INDEX idx_activity_logs_created_at ON activity_logs;`,
+	})
+}
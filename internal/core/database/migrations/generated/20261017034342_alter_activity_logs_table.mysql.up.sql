@@ -0,0 +1 @@
+CREATE INDEX idx_activity_logs_created_at ON activity_logs (created_at);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX ftx_activity_logs_path_raw_path ON activity_logs;
\ No newline at end of file
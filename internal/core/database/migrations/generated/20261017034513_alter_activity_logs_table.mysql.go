@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"study1/internal/core/database"
+)
+
+func init() {
+	database.RegisterMigration(&database.Migration{
+		Version: "20261017034513",
+		Name:    "alter_activity_logs_table",
+		Dialect: "mysql",
+		Up:      `CREATE FULLTEXT INDEX ftx_activity_logs_path_raw_path ON activity_logs (path, raw_path);`,
+		Down:    `DROP INDEX ftx_activity_logs_path_raw_path ON activity_logs;`,
+	})
+}
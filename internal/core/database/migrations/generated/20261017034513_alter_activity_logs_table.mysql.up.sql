@@ -0,0 +1 @@
+CREATE FULLTEXT INDEX ftx_activity_logs_path_raw_path ON activity_logs (path, raw_path);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX ftx_users_name_email ON users;
\ No newline at end of file
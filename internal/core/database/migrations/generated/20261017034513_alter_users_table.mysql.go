@@ -0,0 +1,15 @@
+package migrations
+
+import (
+	"study1/internal/core/database"
+)
+
+func init() {
+	database.RegisterMigration(&database.Migration{
+		Version: "20261017034513",
+		Name:    "alter_users_table",
+		Dialect: "mysql",
+		Up:      `CREATE FULLTEXT INDEX ftx_users_name_email ON users (name, email);`,
+		Down:    `DROP INDEX ftx_users_name_email ON users;`,
+	})
+}
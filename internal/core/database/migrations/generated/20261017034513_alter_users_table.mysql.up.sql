@@ -0,0 +1 @@
+CREATE FULLTEXT INDEX ftx_users_name_email ON users (name, email);
\ No newline at end of file
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"study1/internal/core/database"
+)
+
+func init() {
+	database.RegisterMigration(&database.Migration{
+		Version: "20261017041317",
+		Name:    "create_activity_logs_table",
+		Dialect: "postgres",
+		Up: `CREATE TABLE IF NOT EXISTS activity_logs (
+  id INTEGER NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  uuid UUID NOT NULL,
+  request_id VARCHAR(64) NULL,
+  method VARCHAR(16) NULL,
+  path VARCHAR(1024) NULL,
+  raw_path VARCHAR(2048) NULL,
+  query VARCHAR(2048) NULL,
+  status INTEGER NULL,
+  response_size INTEGER NULL,
+  latency_ms BIGINT NULL,
+  ip VARCHAR(64) NULL,
+  user_agent VARCHAR(512) NULL,
+  user_id INTEGER NULL,
+  errors text NULL,
+  request_body text NULL,
+  response_body text NULL,
+  created_at TIMESTAMPTZ NULL,
+  created_by INTEGER NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX uidx_activity_logs_uuid ON activity_logs (uuid);
+CREATE INDEX idx_activity_logs_request_id ON activity_logs (request_id);
+CREATE INDEX idx_activity_logs_created_at ON activity_logs (created_at);
+CREATE INDEX ftx_activity_logs_path_raw_path ON activity_logs USING GIN (to_tsvector('simple', regexp_replace(coalesce(path, '') || ' ' || coalesce(raw_path, ''), '[/@._-]', ' ', 'g')));`,
+		Down: `DROP TABLE IF EXISTS activity_logs;`,
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"study1/internal/core/database"
+)
+
+func init() {
+	database.RegisterMigration(&database.Migration{
+		Version: "20261017041317",
+		Name:    "create_users_table",
+		Dialect: "postgres",
+		Up: `CREATE TABLE IF NOT EXISTS users (
+  id INTEGER NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  uuid UUID NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  email VARCHAR(100) NOT NULL,
+  age int NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NULL,
+  created_by INTEGER NULL,
+  updated_at TIMESTAMPTZ NULL,
+  updated_by INTEGER NULL,
+  deleted_at TIMESTAMPTZ NULL,
+  deleted_by INTEGER NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX uidx_users_uuid ON users (uuid);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX ftx_users_name_email ON users USING GIN (to_tsvector('simple', regexp_replace(coalesce(name, '') || ' ' || coalesce(email, ''), '[/@._-]', ' ', 'g')));`,
+		Down: `DROP TABLE IF EXISTS users;`,
+	})
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS activity_logs;
\ No newline at end of file
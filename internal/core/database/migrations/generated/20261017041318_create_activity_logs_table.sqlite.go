@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"study1/internal/core/database"
+)
+
+func init() {
+	database.RegisterMigration(&database.Migration{
+		Version: "20261017041318",
+		Name:    "create_activity_logs_table",
+		Dialect: "sqlite",
+		Up: `CREATE TABLE IF NOT EXISTS activity_logs (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  uuid VARCHAR(36) NOT NULL,
+  request_id VARCHAR(64) NULL,
+  method VARCHAR(16) NULL,
+  path VARCHAR(1024) NULL,
+  raw_path VARCHAR(2048) NULL,
+  query VARCHAR(2048) NULL,
+  status INTEGER NULL,
+  response_size INTEGER NULL,
+  latency_ms INTEGER NULL,
+  ip VARCHAR(64) NULL,
+  user_agent VARCHAR(512) NULL,
+  user_id INTEGER NULL,
+  errors text NULL,
+  request_body text NULL,
+  response_body text NULL,
+  created_at DATETIME NULL,
+  created_by INTEGER NULL
+);
+
+CREATE UNIQUE INDEX uidx_activity_logs_uuid ON activity_logs (uuid);
+CREATE INDEX idx_activity_logs_request_id ON activity_logs (request_id);
+CREATE INDEX idx_activity_logs_created_at ON activity_logs (created_at);`,
+		Down: `DROP TABLE IF EXISTS activity_logs;`,
+	})
+}
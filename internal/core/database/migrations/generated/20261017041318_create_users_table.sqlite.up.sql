@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS users (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  uuid VARCHAR(36) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  email VARCHAR(100) NOT NULL,
+  age int NULL DEFAULT 0,
+  created_at DATETIME NULL,
+  created_by INTEGER NULL,
+  updated_at DATETIME NULL,
+  updated_by INTEGER NULL,
+  deleted_at DATETIME NULL,
+  deleted_by INTEGER NULL
+);
+
+CREATE UNIQUE INDEX uidx_users_uuid ON users (uuid);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
\ No newline at end of file
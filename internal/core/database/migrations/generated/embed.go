@@ -0,0 +1,9 @@
+package migrations
+
+import "embed"
+
+// FS holds the generated *.up.sql and *.down.sql files so the binary can
+// apply migrations without the source tree on disk.
+//
+//go:embed *.sql
+var FS embed.FS
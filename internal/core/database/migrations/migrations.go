@@ -1,87 +1,112 @@
 package migrations
 
 import (
+	"errors"
+	"fmt"
 	"log"
-	"os"
-	"path/filepath"
-	"reflect"
-	"sort"
 	"strings"
 
 	"study1/internal/core/database"
-	"study1/internal/modules/activity"
-	"study1/internal/modules/user"
+	generated "study1/internal/core/database/migrations/generated"
 
 	"gorm.io/gorm"
 )
 
-// tableNameOf returns the inferred table name for a model (simple plural snake_case).
-func tableNameOf(model interface{}) string {
-	t := reflect.TypeOf(model)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
-	name := t.Name()
-	// simple snake_case conversion + plural
-	// reuse basic heuristic: lowercased + "s"
-	// For more accurate column names, models can implement TableName().
-	return toSnakeSimple(name) + "s"
-}
+// GeneratedDir is where generated migration files (.go, .up.sql, .down.sql) live.
+const GeneratedDir = "internal/core/database/migrations/generated"
 
-func toSnakeSimple(s string) string {
-	var result []rune
-	for i, r := range s {
-		if r >= 'A' && r <= 'Z' {
-			if i > 0 {
-				result = append(result, '_')
-			}
-			if r >= 'A' && r <= 'Z' {
-				r = r + ('a' - 'A')
-			}
-			result = append(result, r)
-		} else {
-			result = append(result, r)
-		}
-	}
-	return string(result)
-}
+// ErrChecksumMismatch is returned when an applied migration's SQL has been
+// edited since it ran.
+var ErrChecksumMismatch = errors.New("applied migrations have changed since they ran")
 
 type Migration struct {
 	DB *gorm.DB
+	// Sources are merged by version and name to form the single, ordered
+	// list of migrations to apply.
+	Sources []database.MigrationSource
+	// Force lets rollbacks remove the records of migrations that have no
+	// Down SQL.
+	Force bool
+
+	// dryRun is set when DB records statements instead of running them.
+	dryRun *dryRunState
 }
 
+// dryRunState tracks what earlier steps of a dry run would have changed in
+// the migrations table, since the recorded statements are never applied.
+type dryRunState struct {
+	// dropped is set once every table, including the migrations table, would
+	// have been dropped.
+	dropped bool
+	// removed holds the version_name of migrations rolled back so far.
+	removed map[string]bool
+}
+
+// NewMigration creates a Migration reading from the registry, the SQL files
+// embedded from the generated directory and that directory on disk (which
+// also picks up files generated after the binary was built). Only
+// migrations for db's dialect, or for every dialect, are read.
 func NewMigration(db *gorm.DB) *Migration {
-	return &Migration{DB: db}
+	dialect := db.Dialector.Name()
+	m := &Migration{
+		DB: db,
+		Sources: []database.MigrationSource{
+			database.RegistrySource{Dialect: dialect},
+			&database.FSSource{Label: "embedded", FS: generated.FS, Dialect: dialect},
+			database.NewDirSource(GeneratedDir, dialect),
+		},
+	}
+	if database.IsDryRun(db) {
+		m.dryRun = &dryRunState{removed: make(map[string]bool)}
+	}
+	return m
+}
+
+// Migrations returns the merged, ordered migrations from all sources.
+func (m *Migration) Migrations() ([]*database.Migration, error) {
+	return database.MergeSources(m.Sources...)
 }
 
-// RunAll runs all migrations
+// RunAll runs all migrations while holding the cross-process migration lock.
 func (m *Migration) RunAll() error {
+	return m.withLock(m.runAll)
+}
+
+func (m *Migration) runAll() error {
 	migrator := database.NewMigrator(m.DB)
+	if err := migrator.EnsureMigrationsTable(); err != nil {
+		return fmt.Errorf("ensure migrations table: %w", err)
+	}
 
-	// List all models to migrate
-	models := []interface{}{
-		&user.User{},
-		&activity.ActivityLog{},
-		// Add more models here as you create them
+	migs, err := m.Migrations()
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool)
+	for _, mig := range migs {
+		known[mig.Name] = true
 	}
 
-	// Generate migrations for models that don't have tables yet.
-	migrationsDir := "internal/core/database/migrations/generated"
-	generator := database.NewMigrationGenerator(m.DB, migrationsDir)
+	// Generate migrations for models that don't have tables yet. A dry run
+	// prints the files instead of writing them; the generated migrations are
+	// still registered in memory.
+	generator, err := database.NewMigrationGenerator(m.DB, GeneratedDir)
+	if err != nil {
+		return err
+	}
 
 	generatedAny := false
-	for _, model := range models {
-		tableName := tableNameOf(model)
-		if migrator.TableExists(model) {
+	for _, reg := range database.ModelRegistrations() {
+		model, tableName := reg.Model, reg.Table
+		if m.tableExists(migrator, model) {
 			// already migrated
 			continue
 		}
 
 		migrationName := "create_" + tableName + "_table"
-		// Check if migration file already exists in migrations directory
-		pattern := filepath.Join(migrationsDir, "*_"+migrationName+".go")
-		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
-			log.Printf("⚠️  Migration file for %s already exists, skipping generation", tableName)
+		// Check if a migration already exists in any source
+		if known[migrationName] {
+			log.Printf("⚠️  Migration for %s already exists, skipping generation", tableName)
 			continue
 		}
 
@@ -91,183 +116,235 @@ func (m *Migration) RunAll() error {
 		generatedAny = true
 	}
 
-	// Apply any SQL files found in the generated migrations directory.
-	// This covers the case when migration .go files exist on disk but are not
-	// compiled into the running binary (so their init() didn't register them).
-	if err := m.applySQLMigrationsFromDir(migrationsDir); err != nil {
-		return err
+	if generatedAny {
+		// Reload so the migrations generated above are included
+		if migs, err = m.Migrations(); err != nil {
+			return err
+		}
 	}
 
-	// Apply any migrations registered in-memory (including those generated in this run).
-	if err := m.ApplyRegistered(); err != nil {
+	// Refuse to run on top of migrations that were edited after being applied.
+	if err := m.verifyChecksums(migs); err != nil {
 		return err
 	}
 
-	if !generatedAny {
-		// nothing newly generated; ApplyRegistered already ensured any pending registered migrations were applied
-		return nil
-	}
-
-	return nil
+	return m.applyPending(migs)
 }
 
-// applySQLMigrationsFromDir scans the given directory for *.up.sql files and
-// applies any that have not been recorded yet. Files are applied in filename
-// order (which includes timestamp prefix), and after successful execution the
-// migration is recorded.
-func (m *Migration) applySQLMigrationsFromDir(dir string) error {
-	pattern := filepath.Join(dir, "*.up.sql")
-	matches, _ := filepath.Glob(pattern)
-	if len(matches) == 0 {
-		log.Printf("No .up.sql files found in %s", dir)
-		return nil
+// applyPending applies, in order, every migration that has not been recorded
+// yet and records it. Each migration and its record share a transaction
+// where the dialect allows.
+func (m *Migration) applyPending(migs []*database.Migration) error {
+	recs, err := m.appliedRecords()
+	if err != nil {
+		return err
+	}
+	applied := make(map[string]bool, len(recs))
+	for _, rec := range recs {
+		applied[rec.Version+"_"+rec.Name] = true
 	}
 
-	sort.Strings(matches)
-	migrator := database.NewMigrator(m.DB)
-
-	log.Printf("Found %d .up.sql files in %s", len(matches), dir)
-	for _, p := range matches {
-		base := filepath.Base(p)
-		parts := strings.SplitN(base, "_", 2)
-		if len(parts) < 2 {
-			log.Printf("Skipping file with unexpected name: %s", base)
+	for _, mig := range migs {
+		if applied[mig.Version+"_"+mig.Name] {
 			continue
 		}
-		version := parts[0]
-		name := strings.TrimSuffix(parts[1], ".up.sql")
 
-		// Check whether this exact file has already been applied. We include the
-		// filename in the migration record to avoid collisions when multiple
-		// migrations are generated in the same second (same version).
-		applied, err := migrator.HasMigrationRecordWithFile(version, name, base)
+		log.Printf("Applying migration: %s (%s) from %s", mig.Version, mig.Name, mig.Source)
+		err = m.transaction(func(tx *gorm.DB) error {
+			if err := execStatements(tx, mig.Up); err != nil {
+				return err
+			}
+			return database.NewMigrator(tx).RecordMigration(mig.Version, mig.Name, database.Checksum(mig.Up))
+		})
 		if err != nil {
-			return err
-		}
-		if applied {
-			log.Printf("Skipping already-applied migration file: %s", base)
-			continue
+			return fmt.Errorf("apply %s_%s: %w", mig.Version, mig.Name, err)
 		}
+		log.Printf("Applied and recorded migration: %s (%s)", mig.Version, mig.Name)
+	}
 
-		log.Printf("Applying SQL migration: %s (%s)", version, name)
-		content, err := os.ReadFile(p)
-		if err != nil {
-			return err
-		}
+	return nil
+}
 
-		if len(content) > 0 {
-			// Some SQL files contain multiple statements (CREATE TABLE then CREATE INDEX).
-			// The MySQL driver disallows executing multiple statements in one Exec unless
-			// `multiStatements=true` is enabled in the DSN. To avoid changing DSN and
-			// improve portability, split the file by semicolons and execute each
-			// non-empty statement individually.
-			sqlText := string(content)
-			stmts := strings.Split(sqlText, ";")
-			for _, stmt := range stmts {
-				stmt = strings.TrimSpace(stmt)
-				if stmt == "" {
-					continue
-				}
-				if err := m.DB.Exec(stmt).Error; err != nil {
-					return err
-				}
-			}
-			log.Printf("Applied SQL migration: %s", base)
-		} else {
-			log.Printf("Empty SQL file, skipping execution: %s", base)
+// execStatements runs a migration's SQL one statement at a time. Some
+// migrations contain multiple statements (CREATE TABLE then CREATE INDEX).
+// The MySQL driver disallows executing multiple statements in one Exec unless
+// `multiStatements=true` is enabled in the DSN. To avoid changing DSN and
+// improve portability, split the SQL by semicolons and execute each
+// non-empty statement individually.
+func execStatements(db *gorm.DB, sqlText string) error {
+	for _, stmt := range strings.Split(sqlText, ";") {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" {
+			continue
 		}
-
-		if err := migrator.RecordMigrationWithFile(version, name, base); err != nil {
+		if err := db.Exec(stmt).Error; err != nil {
 			return err
 		}
-		log.Printf("Recorded migration: %s (file=%s)", version, base)
 	}
-
 	return nil
 }
 
-// DropAll drops all tables
-func (m *Migration) DropAll() error {
-	migrator := database.NewMigrator(m.DB)
+// transaction runs fn inside a transaction on dialects with transactional
+// DDL (Postgres, SQLite) so a failed migration leaves no partial schema or
+// record behind. MySQL commits implicitly on DDL, so fn runs directly there.
+func (m *Migration) transaction(fn func(tx *gorm.DB) error) error {
+	if !database.SupportsTransactionalDDL(m.DB) {
+		return fn(m.DB)
+	}
+	return m.DB.Transaction(fn)
+}
 
-	models := []interface{}{
-		&user.User{},
-		// Add more models here as you create them
+// appliedRecords returns the applied migration records, adjusted for what
+// earlier steps of a dry run would have changed.
+func (m *Migration) appliedRecords() ([]database.MigrationRecord, error) {
+	if m.dryRun != nil && m.dryRun.dropped {
+		return nil, nil
+	}
+	recs, err := database.NewMigrator(m.DB).AppliedMigrations()
+	if err != nil || m.dryRun == nil {
+		return recs, err
 	}
 
-	return migrator.DropTables(models...)
+	var out []database.MigrationRecord
+	for _, rec := range recs {
+		if !m.dryRun.removed[rec.Version+"_"+rec.Name] {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
 }
 
-// Refresh drops and recreates all tables
-func (m *Migration) Refresh() error {
-	migrator := database.NewMigrator(m.DB)
-
-	models := []interface{}{
-		&user.User{},
-		// Add more models here as you create them
+// tableExists reports whether a model's table exists, treating every table as
+// gone once a dry run has dropped them all.
+func (m *Migration) tableExists(migrator *database.Migrator, model interface{}) bool {
+	if m.dryRun != nil && m.dryRun.dropped {
+		return false
 	}
+	return migrator.TableExists(model)
+}
 
-	return migrator.Refresh(models...)
+// withLock runs fn while holding the cross-process migration lock.
+func (m *Migration) withLock(fn func() error) error {
+	release, err := database.AcquireMigrationLock(m.DB)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := release(); err != nil {
+			log.Printf("Failed to release migration lock: %v", err)
+		}
+	}()
+	return fn()
 }
 
-// ApplyRegistered applies SQL migrations registered in the central registry.
-// Only migrations that are not yet recorded will be executed.
-func (m *Migration) ApplyRegistered() error {
+// verifyChecksums compares every applied migration with its current SQL and
+// fails with ErrChecksumMismatch if any was edited after it ran. Records
+// written before checksums were tracked are stamped with the current one.
+func (m *Migration) verifyChecksums(migs []*database.Migration) error {
 	migrator := database.NewMigrator(m.DB)
+	recs, err := m.appliedRecords()
+	if err != nil {
+		return err
+	}
 
-	regs := database.GetMigrations()
-	for _, reg := range regs {
-		applied, err := migrator.HasMigrationRecordVersionName(reg.Version, reg.Name)
-		if err != nil {
-			return err
-		}
-		if applied {
+	byKey := make(map[string]*database.Migration, len(migs))
+	for _, mig := range migs {
+		byKey[mig.Version+"_"+mig.Name] = mig
+	}
+
+	var changed []string
+	for _, rec := range recs {
+		mig, ok := byKey[rec.Version+"_"+rec.Name]
+		if !ok {
 			continue
 		}
 
-		if reg.Up != "" {
-			if err := m.DB.Exec(reg.Up).Error; err != nil {
+		checksum := database.Checksum(mig.Up)
+		if rec.Checksum == "" {
+			if err := migrator.SetMigrationChecksum(rec.ID, checksum); err != nil {
 				return err
 			}
+			continue
 		}
-
-		if err := migrator.RecordMigration(reg.Version, reg.Name); err != nil {
-			return err
+		if rec.Checksum != checksum {
+			changed = append(changed, rec.Version+"_"+rec.Name)
 		}
 	}
 
+	if len(changed) > 0 {
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(changed, ", "))
+	}
 	return nil
 }
 
-// RollbackRegistered rolls back the specified registered migration (by version).
-// If version is empty, it will roll back the last applied migration.
-func (m *Migration) RollbackRegistered(version string) error {
+// DropAll drops the tables of every registered model
+func (m *Migration) DropAll() error {
 	migrator := database.NewMigrator(m.DB)
+	return migrator.DropTables(database.Models()...)
+}
 
-	// Determine target version
-	target := version
-	if target == "" {
-		var rec database.MigrationRecord
-		if err := m.DB.Order("applied_at desc").First(&rec).Error; err != nil {
+// Fresh drops all tables together with the migrations table and runs every
+// migration again from scratch.
+func (m *Migration) Fresh() error {
+	return m.withLock(func() error {
+		if err := m.DropAll(); err != nil {
 			return err
 		}
-		target = rec.Version
-	}
+		if err := database.NewMigrator(m.DB).DropMigrationsTable(); err != nil {
+			return fmt.Errorf("failed to drop migrations table: %w", err)
+		}
+		if m.dryRun != nil {
+			m.dryRun.dropped = true
+		}
+		return m.runAll()
+	})
+}
 
-	reg := database.GetMigrationByVersion(target)
-	if reg == nil {
-		return gorm.ErrRecordNotFound
-	}
+// Refresh drops and recreates the tables of every registered model
+func (m *Migration) Refresh() error {
+	migrator := database.NewMigrator(m.DB)
+	return migrator.Refresh(database.Models()...)
+}
 
-	if reg.Down != "" {
-		if err := m.DB.Exec(reg.Down).Error; err != nil {
+// ApplyRegistered applies pending migrations from all sources without
+// generating any, holding the migration lock like RunAll. Only migrations
+// that are not yet recorded will be executed, and none if an applied one
+// has changed since it ran.
+func (m *Migration) ApplyRegistered() error {
+	return m.withLock(func() error {
+		if err := database.NewMigrator(m.DB).EnsureMigrationsTable(); err != nil {
+			return fmt.Errorf("ensure migrations table: %w", err)
+		}
+		migs, err := m.Migrations()
+		if err != nil {
 			return err
 		}
-	}
+		if err := m.verifyChecksums(migs); err != nil {
+			return err
+		}
+		return m.applyPending(migs)
+	})
+}
 
-	if err := migrator.RemoveMigrationRecord(target, reg.Name); err != nil {
+// RollbackRegistered rolls back the specified registered migration (by version).
+// If version is empty, it will roll back the last applied migration.
+func (m *Migration) RollbackRegistered(version string) error {
+	if version == "" {
+		_, err := m.Rollback(1)
 		return err
 	}
 
-	return nil
+	return m.withLock(func() error {
+		migrator := database.NewMigrator(m.DB)
+		recs, err := migrator.AppliedMigrations()
+		if err != nil {
+			return err
+		}
+		for i := len(recs) - 1; i >= 0; i-- {
+			if recs[i].Version == version {
+				return m.rollbackRecord(recs[i])
+			}
+		}
+		return gorm.ErrRecordNotFound
+	})
 }
@@ -0,0 +1,114 @@
+package migrations
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"study1/internal/core/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	// A file, so ApplyRegistered takes the migration lock next to it.
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "app.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func sqlSource(label, upSQL string) *database.FSSource {
+	return &database.FSSource{Label: label, Dialect: database.DriverSQLite, FS: fstest.MapFS{
+		"20260101000000_create_things_table.up.sql":   {Data: []byte(upSQL)},
+		"20260101000000_create_things_table.down.sql": {Data: []byte("DROP TABLE things;")},
+	}}
+}
+
+// The same migration read from several sources must get one checksum, whichever
+// source MergeSources reads first.
+func TestChecksumDoesNotDependOnSourceOrder(t *testing.T) {
+	embedded := sqlSource("embedded", "CREATE TABLE things (id INTEGER);")
+	dir := sqlSource("dir", "\nCREATE TABLE things (id INTEGER);\n\n")
+
+	var checksums []string
+	for _, sources := range [][]database.MigrationSource{{embedded, dir}, {dir, embedded}} {
+		migs, err := database.MergeSources(sources...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(migs) != 1 {
+			t.Fatalf("got %d migrations, want 1", len(migs))
+		}
+		checksums = append(checksums, database.Checksum(migs[0].Up))
+	}
+	if checksums[0] != checksums[1] {
+		t.Errorf("checksums differ by source order: %s, %s", checksums[0], checksums[1])
+	}
+}
+
+func TestApplyRegisteredVerifiesChecksums(t *testing.T) {
+	db := openTestDB(t)
+	m := &Migration{DB: db, Sources: []database.MigrationSource{sqlSource("dir", "CREATE TABLE things (id INTEGER);")}}
+	if err := m.ApplyRegistered(); err != nil {
+		t.Fatalf("first apply: %v", err)
+	}
+	if !db.Migrator().HasTable("things") {
+		t.Fatal("things table was not created")
+	}
+
+	// Editing the applied migration must stop the next run.
+	m.Sources = []database.MigrationSource{sqlSource("dir", "CREATE TABLE things (id INTEGER, name TEXT);")}
+	if err := m.ApplyRegistered(); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("apply after edit = %v, want ErrChecksumMismatch", err)
+	}
+}
+
+func TestRollbackWithoutDownSQL(t *testing.T) {
+	tests := []struct {
+		name       string
+		force      bool
+		wantErr    bool
+		wantRecord bool
+	}{
+		{"refused", false, true, true},
+		{"forced", true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			m := &Migration{DB: db, Sources: []database.MigrationSource{&database.FSSource{Label: "dir", Dialect: database.DriverSQLite, FS: fstest.MapFS{
+				"20260101000000_create_things_table.up.sql": {Data: []byte("CREATE TABLE things (id INTEGER);")},
+			}}}}
+			if err := m.ApplyRegistered(); err != nil {
+				t.Fatal(err)
+			}
+
+			m.Force = tt.force
+			_, err := m.Rollback(1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Rollback() error = %v, want error %v", err, tt.wantErr)
+			}
+			recs, err := database.NewMigrator(db).AppliedMigrations()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(recs) == 1; got != tt.wantRecord {
+				t.Errorf("record kept = %v, want %v", got, tt.wantRecord)
+			}
+			if !db.Migrator().HasTable("things") {
+				t.Error("things table was dropped without down SQL")
+			}
+		})
+	}
+}
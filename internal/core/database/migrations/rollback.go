@@ -0,0 +1,203 @@
+package migrations
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"study1/internal/core/database"
+
+	"gorm.io/gorm"
+)
+
+// MigrationStatus describes a known migration and whether it has been applied.
+type MigrationStatus struct {
+	Version   string
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Missing is set when a migration is recorded as applied but no source
+	// knows about it anymore.
+	Missing bool
+}
+
+// Status lists every migration known from the sources and the migrations
+// table, ordered by version.
+func (m *Migration) Status() ([]MigrationStatus, error) {
+	migrator := database.NewMigrator(m.DB)
+	recs, err := migrator.AppliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	migs, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]*MigrationStatus)
+	key := func(version, name string) string { return version + "_" + name }
+
+	for _, mig := range migs {
+		statuses[key(mig.Version, mig.Name)] = &MigrationStatus{Version: mig.Version, Name: mig.Name}
+	}
+
+	for _, rec := range recs {
+		st, exists := statuses[key(rec.Version, rec.Name)]
+		if !exists {
+			st = &MigrationStatus{Version: rec.Version, Name: rec.Name, Missing: true}
+			statuses[key(rec.Version, rec.Name)] = st
+		}
+		if !st.Applied || rec.AppliedAt.Before(st.AppliedAt) {
+			st.AppliedAt = rec.AppliedAt
+		}
+		st.Applied = true
+	}
+
+	result := make([]MigrationStatus, 0, len(statuses))
+	for _, st := range statuses {
+		result = append(result, *st)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Version != result[j].Version {
+			return result[i].Version < result[j].Version
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// Rollback reverts the last `steps` applied migrations, newest first, using
+// their Down SQL. It returns the migrations that were rolled back.
+func (m *Migration) Rollback(steps int) (rolledBack []database.MigrationRecord, err error) {
+	err = m.withLock(func() error {
+		rolledBack, err = m.rollback(steps)
+		return err
+	})
+	return rolledBack, err
+}
+
+func (m *Migration) rollback(steps int) ([]database.MigrationRecord, error) {
+	applied, err := m.appliedNewestFirst()
+	if err != nil {
+		return nil, err
+	}
+	if steps < len(applied) {
+		applied = applied[:steps]
+	}
+	return m.rollbackRecords(applied)
+}
+
+// RollbackTo reverts every applied migration newer than version, leaving
+// version itself applied. An empty version rolls back everything.
+func (m *Migration) RollbackTo(version string) (rolledBack []database.MigrationRecord, err error) {
+	err = m.withLock(func() error {
+		rolledBack, err = m.rollbackTo(version)
+		return err
+	})
+	return rolledBack, err
+}
+
+func (m *Migration) rollbackTo(version string) ([]database.MigrationRecord, error) {
+	applied, err := m.appliedNewestFirst()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []database.MigrationRecord
+	for _, rec := range applied {
+		if rec.Version > version {
+			targets = append(targets, rec)
+		}
+	}
+	return m.rollbackRecords(targets)
+}
+
+// Redo rolls back the last `steps` migrations and runs migrations again.
+func (m *Migration) Redo(steps int) error {
+	return m.withLock(func() error {
+		if _, err := m.rollback(steps); err != nil {
+			return err
+		}
+		return m.runAll()
+	})
+}
+
+// appliedNewestFirst returns one record per applied migration, most recently
+// applied first. Older runs could record the same migration once from the
+// registry and once from its .up.sql file; only the latest record is kept.
+func (m *Migration) appliedNewestFirst() ([]database.MigrationRecord, error) {
+	recs, err := m.appliedRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var out []database.MigrationRecord
+	for i := len(recs) - 1; i >= 0; i-- {
+		k := recs[i].Version + "_" + recs[i].Name
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, recs[i])
+	}
+	return out, nil
+}
+
+func (m *Migration) rollbackRecords(recs []database.MigrationRecord) ([]database.MigrationRecord, error) {
+	var done []database.MigrationRecord
+	for _, rec := range recs {
+		if err := m.rollbackRecord(rec); err != nil {
+			return done, fmt.Errorf("rollback %s_%s: %w", rec.Version, rec.Name, err)
+		}
+		done = append(done, rec)
+	}
+	return done, nil
+}
+
+// rollbackRecord runs the Down SQL for a recorded migration and removes its
+// records. A migration without Down SQL keeps its records unless m.Force is
+// set, as removing them would leave its schema changes unrecorded.
+func (m *Migration) rollbackRecord(rec database.MigrationRecord) error {
+	downSQL, err := m.downSQL(rec.Version, rec.Name)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(downSQL) == "" {
+		if !m.Force {
+			return fmt.Errorf("migration %s_%s has no down SQL", rec.Version, rec.Name)
+		}
+		log.Printf("⚠️  Migration %s (%s) has no down SQL; removing its record and leaving its schema changes in place", rec.Version, rec.Name)
+	}
+
+	log.Printf("Rolling back migration: %s (%s)", rec.Version, rec.Name)
+	err = m.transaction(func(tx *gorm.DB) error {
+		if err := execStatements(tx, downSQL); err != nil {
+			return err
+		}
+		return database.NewMigrator(tx).RemoveMigrationRecord(rec.Version, rec.Name)
+	})
+	if err != nil {
+		return err
+	}
+	if m.dryRun != nil {
+		m.dryRun.removed[rec.Version+"_"+rec.Name] = true
+	}
+	log.Printf("Rolled back migration: %s (%s)", rec.Version, rec.Name)
+	return nil
+}
+
+func (m *Migration) downSQL(version, name string) (string, error) {
+	migs, err := m.Migrations()
+	if err != nil {
+		return "", err
+	}
+	for _, mig := range migs {
+		if mig.Version == version && mig.Name == name {
+			return mig.Down, nil
+		}
+	}
+	return "", fmt.Errorf("no down migration found for %s_%s", version, name)
+}
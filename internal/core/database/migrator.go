@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Migrator struct {
@@ -23,32 +24,53 @@ type MigrationRecord struct {
 	Version   string    `gorm:"size:64;uniqueIndex:idx_migrations_unique"`
 	Name      string    `gorm:"size:255;uniqueIndex:idx_migrations_unique"`
 	File      string    `gorm:"size:255;uniqueIndex:idx_migrations_unique"`
+	Checksum  string    `gorm:"size:64"`
 	AppliedAt time.Time `gorm:"autoCreateTime"`
 }
 
-// ensureMigrationsTable ensures the migrations table exists.
+// ensureMigrationsTable ensures the migrations table exists. A dry run
+// records the CREATE TABLE only once, from EnsureMigrationsTable.
 func (m *Migrator) ensureMigrationsTable() error {
+	if IsDryRun(m.db) {
+		return nil
+	}
+	return m.db.AutoMigrate(&MigrationRecord{})
+}
+
+// EnsureMigrationsTable creates or updates the migrations table.
+func (m *Migrator) EnsureMigrationsTable() error {
 	return m.db.AutoMigrate(&MigrationRecord{})
 }
 
+// recordsVisible reports whether the migrations table can be read. In a dry
+// run it may exist only in the recorded SQL, in which case it reads as empty.
+func (m *Migrator) recordsVisible() bool {
+	return !IsDryRun(m.db) || m.db.Migrator().HasTable(&MigrationRecord{})
+}
+
 // RecordMigration inserts a migration record if it does not already exist.
-func (m *Migrator) RecordMigration(version, name string) error {
+// checksum is the Checksum of the migration's Up SQL.
+func (m *Migrator) RecordMigration(version, name, checksum string) error {
 	if err := m.ensureMigrationsTable(); err != nil {
 		return fmt.Errorf("ensure migrations table: %w", err)
 	}
 
 	var rec MigrationRecord
-	if err := m.db.Where("version = ? AND name = ?", version, name).First(&rec).Error; err == nil {
-		// already recorded
-		return nil
-	} else if err != gorm.ErrRecordNotFound {
-		return fmt.Errorf("check migration record: %w", err)
+	// A dry run only records migrations it found pending, so it skips the check.
+	if !IsDryRun(m.db) {
+		if err := m.db.Where("version = ? AND name = ?", version, name).First(&rec).Error; err == nil {
+			// already recorded
+			return nil
+		} else if err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("check migration record: %w", err)
+		}
 	}
 
 	rec = MigrationRecord{
 		Version:   version,
 		Name:      name,
 		File:      "",
+		Checksum:  checksum,
 		AppliedAt: time.Now(),
 	}
 
@@ -63,6 +85,9 @@ func (m *Migrator) HasMigrationRecord(version string) (bool, error) {
 	if err := m.ensureMigrationsTable(); err != nil {
 		return false, err
 	}
+	if !m.recordsVisible() {
+		return false, nil
+	}
 	var rec MigrationRecord
 	if err := m.db.Where("version = ?", version).First(&rec).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -78,6 +103,9 @@ func (m *Migrator) HasMigrationRecordVersionName(version, name string) (bool, er
 	if err := m.ensureMigrationsTable(); err != nil {
 		return false, err
 	}
+	if !m.recordsVisible() {
+		return false, nil
+	}
 	var rec MigrationRecord
 	if err := m.db.Where("version = ? AND name = ?", version, name).First(&rec).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -93,6 +121,9 @@ func (m *Migrator) HasMigrationRecordWithFile(version, name, file string) (bool,
 	if err := m.ensureMigrationsTable(); err != nil {
 		return false, err
 	}
+	if !m.recordsVisible() {
+		return false, nil
+	}
 	var rec MigrationRecord
 	if err := m.db.Where("version = ? AND name = ? AND file = ?", version, name, file).First(&rec).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -103,6 +134,21 @@ func (m *Migrator) HasMigrationRecordWithFile(version, name, file string) (bool,
 	return true, nil
 }
 
+// AppliedMigrations returns all migration records in the order they were applied.
+func (m *Migrator) AppliedMigrations() ([]MigrationRecord, error) {
+	if err := m.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+	if !m.recordsVisible() {
+		return nil, nil
+	}
+	var recs []MigrationRecord
+	if err := m.db.Order("applied_at asc, id asc").Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
 // RemoveMigrationRecord deletes a migration record by version.
 func (m *Migrator) RemoveMigrationRecord(version, name string) error {
 	if err := m.ensureMigrationsTable(); err != nil {
@@ -115,23 +161,27 @@ func (m *Migrator) RemoveMigrationRecord(version, name string) error {
 }
 
 // RecordMigrationWithFile records a migration including the originating file name.
-func (m *Migrator) RecordMigrationWithFile(version, name, file string) error {
+func (m *Migrator) RecordMigrationWithFile(version, name, file, checksum string) error {
 	if err := m.ensureMigrationsTable(); err != nil {
 		return fmt.Errorf("ensure migrations table: %w", err)
 	}
 
 	var rec MigrationRecord
-	if err := m.db.Where("version = ? AND name = ? AND file = ?", version, name, file).First(&rec).Error; err == nil {
-		// already recorded
-		return nil
-	} else if err != gorm.ErrRecordNotFound {
-		return fmt.Errorf("check migration record: %w", err)
+	// A dry run only records migrations it found pending, so it skips the check.
+	if !IsDryRun(m.db) {
+		if err := m.db.Where("version = ? AND name = ? AND file = ?", version, name, file).First(&rec).Error; err == nil {
+			// already recorded
+			return nil
+		} else if err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("check migration record: %w", err)
+		}
 	}
 
 	rec = MigrationRecord{
 		Version:   version,
 		Name:      name,
 		File:      file,
+		Checksum:  checksum,
 		AppliedAt: time.Now(),
 	}
 
@@ -141,6 +191,12 @@ func (m *Migrator) RecordMigrationWithFile(version, name, file string) error {
 	return nil
 }
 
+// SetMigrationChecksum stores the checksum on an existing record. It is used
+// to stamp records written before checksums were tracked.
+func (m *Migrator) SetMigrationChecksum(id uint, checksum string) error {
+	return m.db.Model(&MigrationRecord{}).Where("id = ?", id).Update("checksum", checksum).Error
+}
+
 // AutoMigrate automatically migrates all models
 func (m *Migrator) AutoMigrate(models ...interface{}) error {
 	log.Println("🔄 Starting database migration...")
@@ -163,24 +219,62 @@ func (m *Migrator) DropTables(models ...interface{}) error {
 	log.Println("🗑️  Dropping all tables...")
 
 	// Disable foreign key checks
-	m.db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	m.setForeignKeyChecks(false)
 
 	for _, model := range models {
 		tableName := getTableName(model)
 		log.Printf("Dropping table: %s", tableName)
 
-		if err := m.db.Migrator().DropTable(model); err != nil {
+		if err := m.dropTable(model); err != nil {
 			return fmt.Errorf("failed to drop table %s: %w", tableName, err)
 		}
 	}
 
 	// Enable foreign key checks
-	m.db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+	m.setForeignKeyChecks(true)
 
 	log.Println("✅ All tables dropped successfully")
 	return nil
 }
 
+// dropTable drops a model's table. gorm's MySQL migrator drops tables on a
+// connection of its own, which a dry run cannot record, so dry runs issue the
+// statement through m.db instead.
+func (m *Migrator) dropTable(model interface{}) error {
+	if !IsDryRun(m.db) {
+		return m.db.Migrator().DropTable(model)
+	}
+	stmt := "DROP TABLE IF EXISTS ?"
+	if m.db.Dialector.Name() != DriverSQLite {
+		stmt += " CASCADE"
+	}
+	return m.db.Exec(stmt, clause.Table{Name: getTableName(model)}).Error
+}
+
+// DropMigrationsTable drops the table that records applied migrations.
+func (m *Migrator) DropMigrationsTable() error {
+	return m.dropTable(&MigrationRecord{})
+}
+
+// setForeignKeyChecks toggles foreign key enforcement on dialects that
+// support it. Postgres drops tables with CASCADE so it needs nothing here.
+func (m *Migrator) setForeignKeyChecks(enabled bool) {
+	switch m.db.Dialector.Name() {
+	case DriverMySQL:
+		if enabled {
+			m.db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+		} else {
+			m.db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+		}
+	case DriverSQLite:
+		if enabled {
+			m.db.Exec("PRAGMA foreign_keys = ON")
+		} else {
+			m.db.Exec("PRAGMA foreign_keys = OFF")
+		}
+	}
+}
+
 // Get table name from model
 func getTableName(model interface{}) string {
 	if tableNamer, ok := model.(interface{ TableName() string }); ok {
@@ -203,7 +297,15 @@ func (m *Migrator) Refresh(models ...interface{}) error {
 	if err := m.DropTables(models...); err != nil {
 		return err
 	}
-	return m.AutoMigrate(models...)
+	// The tables are gone, so create them outright. AutoMigrate would inspect
+	// the live schema, which a dry run leaves untouched.
+	for _, model := range models {
+		log.Printf("Creating table: %s", getTableName(model))
+		if err := m.db.Migrator().CreateTable(model); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", getTableName(model), err)
+		}
+	}
+	return nil
 }
 
 // Check if table exists
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// ModelRegistration records a model and the module that owns its table.
+type ModelRegistration struct {
+	Module string
+	Model  interface{}
+	// TypeName is the Go type name of the model, e.g. "User".
+	TypeName string
+	Table    string
+}
+
+var (
+	modelRegistrations []ModelRegistration
+	tableOwners        = make(map[string]string)
+	schemaCache        sync.Map
+)
+
+// RegisterModels registers the models owned by a module, usually from the
+// module's init function. Migrations, drop/refresh, seeding and the docs
+// generator all read the registry, so a new module only has to register its
+// models here. It panics if a table is already owned by another module.
+func RegisterModels(module string, models ...interface{}) {
+	for _, model := range models {
+		s, err := schema.Parse(model, &schemaCache, schema.NamingStrategy{})
+		if err != nil {
+			panic(fmt.Sprintf("register model %T for module %s: %v", model, module, err))
+		}
+
+		if owner, exists := tableOwners[s.Table]; exists {
+			if owner == module {
+				continue
+			}
+			panic(fmt.Sprintf("table %s is registered by module %s and %s", s.Table, owner, module))
+		}
+		tableOwners[s.Table] = module
+
+		t := reflect.TypeOf(model)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		modelRegistrations = append(modelRegistrations, ModelRegistration{
+			Module:   module,
+			Model:    model,
+			TypeName: t.Name(),
+			Table:    s.Table,
+		})
+	}
+}
+
+// ModelRegistrations returns all registered models in registration order
+func ModelRegistrations() []ModelRegistration {
+	return modelRegistrations
+}
+
+// Models returns all registered models in registration order
+func Models() []interface{} {
+	models := make([]interface{}, 0, len(modelRegistrations))
+	for _, reg := range modelRegistrations {
+		models = append(models, reg.Model)
+	}
+	return models
+}
+
+// ModelsOf returns the models registered by a module
+func ModelsOf(module string) []interface{} {
+	var models []interface{}
+	for _, reg := range modelRegistrations {
+		if reg.Module == module {
+			models = append(models, reg.Model)
+		}
+	}
+	return models
+}
+
+// TableOwner returns the module that owns a table, or "" if none does
+func TableOwner(table string) string {
+	return tableOwners[table]
+}
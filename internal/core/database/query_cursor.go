@@ -0,0 +1,295 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"study1/internal/core/types"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// cursor is the decoded form of the opaque pagination cursor: the sort key
+// of the row a page starts after (or, for Prev, ends before).
+type cursor struct {
+	Sort   string    `json:"s"`
+	Prev   bool      `json:"p,omitempty"`
+	Values []*string `json:"v"`
+}
+
+// keysetState is what BuildKeyset remembers to turn the fetched rows into a
+// page.
+type keysetState struct {
+	terms     []sortTerm
+	limit     int
+	prev      bool
+	hasCursor bool
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil {
+		return c, fmt.Errorf("%w: malformed cursor", types.ErrInvalidQuery)
+	}
+	return c, nil
+}
+
+// sortSignature identifies a sort in a cursor, so a cursor cannot be
+// replayed against a different order.
+func sortSignature(terms []sortTerm) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		part := term.Field.DBName
+		if term.Desc {
+			part = "-" + part
+		}
+		if term.Nulls != nullsDefault {
+			part += ":" + term.Nulls
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, ",")
+}
+
+// reverseTerms flips every term, for reading the rows before a cursor.
+func reverseTerms(terms []sortTerm) []sortTerm {
+	reversed := make([]sortTerm, len(terms))
+	for i, term := range terms {
+		term.Desc = !term.Desc
+		switch term.Nulls {
+		case nullsFirst:
+			term.Nulls = nullsLast
+		case nullsLast:
+			term.Nulls = nullsFirst
+		}
+		reversed[i] = term
+	}
+	return reversed
+}
+
+// nullsAfter reports whether NULLs come after other values in term's order.
+// Without explicit placement that follows the database: PostgreSQL sorts
+// NULLs as the largest values, MySQL and SQLite as the smallest.
+func nullsAfter(term sortTerm, dialect string) bool {
+	switch term.Nulls {
+	case nullsFirst:
+		return false
+	case nullsLast:
+		return true
+	}
+	return (dialect == "postgres") != term.Desc
+}
+
+// keysetCondition matches the rows that come after values in the order of
+// terms: (a > ?) OR (a = ? AND b > ?) OR ..., with NULLs placed as the
+// ORDER BY places them.
+func keysetCondition(terms []sortTerm, values []interface{}, dialect string) clause.Expression {
+	var ors []clause.Expression
+	for i, term := range terms {
+		var ands []clause.Expression
+		for j := 0; j < i; j++ {
+			ands = append(ands, equalExpression(terms[j], values[j]))
+		}
+		after := afterExpression(term, values[i], dialect)
+		if after == nil {
+			continue
+		}
+		ors = append(ors, clause.And(append(ands, after)...))
+	}
+	if len(ors) == 0 {
+		return clause.Expr{SQL: "1 = 0"}
+	}
+	return clause.Or(ors...)
+}
+
+func equalExpression(term sortTerm, value interface{}) clause.Expression {
+	column := clause.Column{Table: clause.CurrentTable, Name: term.Field.DBName}
+	if value == nil {
+		return clause.Expr{SQL: "? IS NULL", Vars: []interface{}{column}}
+	}
+	return clause.Eq{Column: column, Value: value}
+}
+
+// afterExpression matches values strictly after value in term's order, or
+// returns nil when nothing can follow it.
+func afterExpression(term sortTerm, value interface{}, dialect string) clause.Expression {
+	column := clause.Column{Table: clause.CurrentTable, Name: term.Field.DBName}
+	nullable := !term.Field.PrimaryKey && !term.Field.NotNull
+	nullsLater := nullable && nullsAfter(term, dialect)
+
+	if value == nil {
+		if nullsLater {
+			return nil
+		}
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{column}}
+	}
+
+	var cmp clause.Expression = clause.Gt{Column: column, Value: value}
+	if term.Desc {
+		cmp = clause.Lt{Column: column, Value: value}
+	}
+	if nullsLater {
+		return clause.Or(cmp, clause.Expr{SQL: "? IS NULL", Vars: []interface{}{column}})
+	}
+	return cmp
+}
+
+// formatCursorValue renders a sort key value for a cursor; nil stands for
+// NULL.
+func formatCursorValue(v interface{}) *string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+
+	var s string
+	switch x := rv.Interface().(type) {
+	case time.Time:
+		s = x.Format(time.RFC3339Nano)
+	case driver.Valuer:
+		value, err := x.Value()
+		if err != nil || value == nil {
+			return nil
+		}
+		return formatCursorValue(value)
+	default:
+		s = fmt.Sprint(x)
+	}
+	return &s
+}
+
+// BuildKeyset builds the query for one cursor page: Conditions, the
+// keyset condition from the cursor, the sort (reversed when paging back) and
+// a limit one above the page size to tell whether more rows follow. Pass the
+// rows it finds to KeysetPage. Searches keep the sort order rather than
+// relevance, which a cursor cannot point into.
+func (b *QueryBuilder[T]) BuildKeyset() *gorm.DB {
+	b.applyConditions()
+
+	terms, err := b.sortTerms()
+	if err != nil {
+		b.DB.AddError(err)
+		return b.DB
+	}
+	state := &keysetState{terms: terms, limit: b.Params.Limit}
+	if state.limit <= 0 {
+		state.limit = 10
+	}
+
+	if b.Params.Cursor != "" {
+		c, err := decodeCursor(b.Params.Cursor)
+		if err != nil {
+			b.DB.AddError(err)
+			return b.DB
+		}
+		if c.Sort != sortSignature(terms) || len(c.Values) != len(terms) {
+			b.DB.AddError(fmt.Errorf("%w: cursor does not match the sort order", types.ErrInvalidQuery))
+			return b.DB
+		}
+
+		values := make([]interface{}, len(terms))
+		for i, raw := range c.Values {
+			if raw == nil {
+				continue
+			}
+			if values[i], err = convertFilterValue(terms[i].Field, *raw); err != nil {
+				b.DB.AddError(fmt.Errorf("%w: malformed cursor", types.ErrInvalidQuery))
+				return b.DB
+			}
+		}
+
+		state.prev = c.Prev
+		state.hasCursor = true
+		scan := terms
+		if c.Prev {
+			scan = reverseTerms(terms)
+		}
+		b.DB = b.DB.Where(keysetCondition(scan, values, b.DB.Dialector.Name()))
+	}
+
+	order := terms
+	if state.prev {
+		order = reverseTerms(terms)
+	}
+	if len(order) > 0 {
+		b.DB = b.DB.Order(orderByClause(order))
+	}
+	b.DB = b.DB.Limit(state.limit + 1)
+	b.keyset = state
+
+	b.applyRelation()
+	b.applyFieldSelection()
+	return b.DB
+}
+
+// KeysetPage trims rows fetched by BuildKeyset to the page and returns the
+// page's metadata with cursors for the neighbouring pages. There is no
+// previous cursor on the first page and no next cursor on the last.
+func (b *QueryBuilder[T]) KeysetPage(rows []T) ([]T, *types.Meta) {
+	state := b.keyset
+	if state == nil {
+		return rows, &types.Meta{PageSize: len(rows)}
+	}
+
+	more := len(rows) > state.limit
+	if more {
+		rows = rows[:state.limit]
+	}
+	if state.prev {
+		slices.Reverse(rows)
+	}
+
+	meta := &types.Meta{PageSize: state.limit}
+	if len(rows) == 0 {
+		return rows, meta
+	}
+
+	first, last := rows[0], rows[len(rows)-1]
+	if state.prev {
+		if more {
+			meta.PrevCursor = b.cursorFor(first, true)
+		}
+		meta.NextCursor = b.cursorFor(last, false)
+	} else {
+		if more {
+			meta.NextCursor = b.cursorFor(last, false)
+		}
+		if state.hasCursor {
+			meta.PrevCursor = b.cursorFor(first, true)
+		}
+	}
+	return rows, meta
+}
+
+func (b *QueryBuilder[T]) cursorFor(row T, prev bool) string {
+	c := cursor{Sort: sortSignature(b.keyset.terms), Prev: prev}
+	rv := reflect.ValueOf(row)
+	for _, term := range b.keyset.terms {
+		value, _ := term.Field.ValueOf(context.Background(), rv)
+		c.Values = append(c.Values, formatCursorValue(value))
+	}
+	return encodeCursor(c)
+}
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"study1/internal/core/types"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []cursor{
+		{Sort: "age,id", Values: []*string{strPtr("30"), strPtr("2")}},
+		{Sort: "-score:nulls_last,-id", Prev: true, Values: []*string{nil, strPtr("4")}},
+		{Sort: "name,id", Values: []*string{strPtr(`quote " and , comma`), strPtr("1")}},
+	}
+	for _, want := range tests {
+		got, err := decodeCursor(encodeCursor(want))
+		if err != nil {
+			t.Fatalf("decode %+v: %v", want, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip: got %+v, want %+v", got, want)
+		}
+	}
+}
+
+// Cursors are opaque but not signed; whatever a client sends back must be
+// rejected as a bad query rather than reach the database or fail with 500.
+func TestKeysetRejectsTamperedCursors(t *testing.T) {
+	db := openQueryDB(t)
+	valid := cursor{Sort: "age,id", Values: []*string{strPtr("30"), strPtr("2")}}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "not a cursor!"},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("age=30"))},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"age,id","v":["30","2"]}`))},
+		{"other sort", encodeCursor(cursor{Sort: "name,id", Values: valid.Values})},
+		{"sort signature edited", encodeCursor(cursor{Sort: "-age,id", Values: valid.Values})},
+		{"too few values", encodeCursor(cursor{Sort: valid.Sort, Values: valid.Values[:1]})},
+		{"too many values", encodeCursor(cursor{Sort: valid.Sort, Values: append(valid.Values, strPtr("3"))})},
+		{"value of wrong type", encodeCursor(cursor{Sort: valid.Sort, Values: []*string{strPtr("thirty"), strPtr("2")}})},
+		{"injected value", encodeCursor(cursor{Sort: valid.Sort, Values: []*string{strPtr("30) OR (1=1"), strPtr("2")}})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []queryItem
+			builder := NewQueryBuilder[queryItem](db.DB, types.QueryParams{Sort: "age", Limit: 2, Cursor: tt.cursor})
+			err := builder.BuildKeyset().Find(&rows).Error
+			if !errors.Is(err, types.ErrInvalidQuery) {
+				t.Errorf("got %d rows, %v; want types.ErrInvalidQuery", len(rows), err)
+			}
+		})
+	}
+}
+
+// keysetPage reads the page params points at.
+func keysetPage(t *testing.T, db *DB, params types.QueryParams) ([]string, *types.Meta) {
+	t.Helper()
+	var rows []queryItem
+	builder := NewQueryBuilder[queryItem](db.DB, params)
+	if err := builder.BuildKeyset().Find(&rows).Error; err != nil {
+		t.Fatalf("page %+v: %v", params, err)
+	}
+	rows, meta := builder.KeysetPage(rows)
+	names := make([]string, len(rows))
+	for i, row := range rows {
+		names[i] = row.Name
+	}
+	return names, meta
+}
+
+// TestKeysetPaging pages through the fixture two rows at a time, forward to
+// the end and back to the start, for sorts over repeated ages and NULL
+// scores. The pages must cover every row once, in the order of the sort.
+func TestKeysetPaging(t *testing.T) {
+	db := openQueryDB(t)
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		// Equal ages are ordered by the id tie-breaker.
+		{"age", []string{"Andi", "Eka", "Budi", "Citra", "Dewi"}},
+		{"-age", []string{"Dewi", "Citra", "Budi", "Eka", "Andi"}},
+		{"age,-name", []string{"Eka", "Andi", "Citra", "Budi", "Dewi"}},
+		// SQLite sorts NULLs first ascending and last descending.
+		{"score", []string{"Budi", "Dewi", "Andi", "Eka", "Citra"}},
+		{"-score", []string{"Citra", "Eka", "Andi", "Dewi", "Budi"}},
+		{"score:nulls_last", []string{"Andi", "Eka", "Citra", "Budi", "Dewi"}},
+		{"-score:nulls_first", []string{"Dewi", "Budi", "Citra", "Eka", "Andi"}},
+		{"age,score:nulls_last", []string{"Andi", "Eka", "Citra", "Budi", "Dewi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			// The offset query must agree, or the expectation is wrong.
+			all, err := listNames(db, types.QueryParams{Sort: tt.sort, PageSize: 100})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(all, tt.want) {
+				t.Fatalf("offset order %v, want %v", all, tt.want)
+			}
+
+			params := types.QueryParams{Sort: tt.sort, Limit: 2}
+			var forward [][]string
+			var meta *types.Meta
+			for {
+				var page []string
+				page, meta = keysetPage(t, db, params)
+				if len(forward) == 0 && meta.PrevCursor != "" {
+					t.Error("first page has a previous cursor")
+				}
+				forward = append(forward, page)
+				if meta.NextCursor == "" {
+					break
+				}
+				if len(forward) > len(tt.want) {
+					t.Fatalf("paging forward does not end: %v", forward)
+				}
+				params.Cursor = meta.NextCursor
+			}
+			if got := slices.Concat(forward...); !slices.Equal(got, tt.want) {
+				t.Fatalf("forward pages %v, want %v", forward, tt.want)
+			}
+
+			// Back from the last page, which must end up at the first.
+			var backward [][]string
+			for meta.PrevCursor != "" {
+				params.Cursor = meta.PrevCursor
+				var page []string
+				page, meta = keysetPage(t, db, params)
+				if meta.NextCursor == "" {
+					t.Errorf("page %v reached backwards has no next cursor", page)
+				}
+				backward = append([][]string{page}, backward...)
+				if len(backward) > len(tt.want) {
+					t.Fatalf("paging backward does not end: %v", backward)
+				}
+			}
+			if want := forward[:len(forward)-1]; !reflect.DeepEqual(backward, want) {
+				t.Errorf("backward pages %v, want %v", backward, want)
+			}
+		})
+	}
+}
+
+// Paging back must reverse both the rows' direction and the NULL placement;
+// otherwise a NULL at the edge of a page would be skipped or repeated.
+func TestKeysetPagesBackAcrossNulls(t *testing.T) {
+	db := openQueryDB(t)
+
+	// Page 2 of "score" two at a time starts on the first non-NULL score.
+	_, first := keysetPage(t, db, types.QueryParams{Sort: "score", Limit: 2})
+	second, meta := keysetPage(t, db, types.QueryParams{Sort: "score", Limit: 2, Cursor: first.NextCursor})
+	if want := []string{"Andi", "Eka"}; !slices.Equal(second, want) {
+		t.Fatalf("second page %v, want %v", second, want)
+	}
+	prev, _ := keysetPage(t, db, types.QueryParams{Sort: "score", Limit: 2, Cursor: meta.PrevCursor})
+	if want := []string{"Budi", "Dewi"}; !slices.Equal(prev, want) {
+		t.Errorf("previous page %v, want %v", prev, want)
+	}
+}
+
+func TestNullsAfter(t *testing.T) {
+	tests := []struct {
+		term    sortTerm
+		dialect string
+		want    bool
+	}{
+		{sortTerm{}, DriverPostgres, true},
+		{sortTerm{Desc: true}, DriverPostgres, false},
+		{sortTerm{}, DriverMySQL, false},
+		{sortTerm{Desc: true}, DriverMySQL, true},
+		{sortTerm{}, DriverSQLite, false},
+		{sortTerm{Desc: true}, DriverSQLite, true},
+		{sortTerm{Nulls: nullsFirst}, DriverPostgres, false},
+		{sortTerm{Desc: true, Nulls: nullsLast}, DriverMySQL, true},
+	}
+	for _, tt := range tests {
+		if got := nullsAfter(tt.term, tt.dialect); got != tt.want {
+			t.Errorf("nullsAfter(%+v, %s) = %v, want %v", tt.term, tt.dialect, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"study1/internal/core/types"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Struct tags that allow a model field in list query parameters, e.g.
+// `sortable:"true" filterable:"true"`. Fields without the tag are rejected.
+const (
+	TagSearchable = "searchable"
+	TagSortable   = "sortable"
+	TagFilterable = "filterable"
+	TagSelectable = "selectable"
+	// TagIncludable goes on association fields that may be preloaded.
+	TagIncludable = "includable"
+)
+
+// schema returns the parsed schema of the builder's model.
+func (b *QueryBuilder[T]) schema() (*schema.Schema, error) {
+	if b.parsed == nil {
+		stmt := &gorm.Statement{DB: b.DB}
+		if err := stmt.Parse(&b.Model); err != nil {
+			return nil, err
+		}
+		b.parsed = stmt.Schema
+	}
+	return b.parsed, nil
+}
+
+// lookupField finds a model column by column name, JSON name or Go field name.
+func lookupField(s *schema.Schema, name string) *schema.Field {
+	if field := s.LookUpField(name); field != nil && field.DBName != "" {
+		return field
+	}
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		if jsonName := strings.Split(field.Tag.Get("json"), ",")[0]; jsonName == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// fieldName is the name clients use for a field: its JSON name, falling back
+// to the column name.
+func fieldName(field *schema.Field) string {
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return field.DBName
+}
+
+// allowedFieldNames lists the client names of the columns carrying tag.
+func allowedFieldNames(s *schema.Schema, tag string) []string {
+	var names []string
+	for _, field := range s.Fields {
+		if field.DBName != "" && field.Tag.Get(tag) == "true" {
+			names = append(names, fieldName(field))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveField returns the column named name if it carries tag, and
+// otherwise a types.ErrInvalidQuery error listing the allowed names. action
+// describes the use in the message, e.g. "sort by".
+func resolveField(s *schema.Schema, tag, name, action string) (*schema.Field, error) {
+	if field := lookupField(s, name); field != nil && field.Tag.Get(tag) == "true" {
+		return field, nil
+	}
+	return nil, fmt.Errorf("%w: cannot %s %q (%s)", types.ErrInvalidQuery, action, name, allowedList(allowedFieldNames(s, tag)))
+}
+
+// resolveRelation returns the association named name if it carries
+// TagIncludable.
+func resolveRelation(s *schema.Schema, name string) (*schema.Relationship, error) {
+	var allowed []string
+	var found *schema.Relationship
+	for relName, rel := range s.Relationships.Relations {
+		if rel.Field.Tag.Get(TagIncludable) != "true" {
+			continue
+		}
+		clientName := fieldName(rel.Field)
+		if clientName == rel.Field.DBName {
+			clientName = relName
+		}
+		allowed = append(allowed, clientName)
+		if name == relName || name == clientName {
+			found = rel
+		}
+	}
+	if found != nil {
+		return found, nil
+	}
+	sort.Strings(allowed)
+	return nil, fmt.Errorf("%w: cannot include %q (%s)", types.ErrInvalidQuery, name, allowedList(allowed))
+}
+
+func allowedList(names []string) string {
+	if len(names) == 0 {
+		return "none allowed"
+	}
+	return "allowed: " + strings.Join(names, ", ")
+}
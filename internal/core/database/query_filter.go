@@ -0,0 +1,136 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"study1/internal/core/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// filterTimeLayouts are accepted for filters on time columns.
+var filterTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// filterExpression turns a filter condition into a clause on field, with its
+// values converted to the field's type. Columns are quoted by gorm, so only
+// names resolved against the model ever reach the SQL. dialect is the name of
+// the database's dialect.
+func filterExpression(field *schema.Field, cond types.FilterCondition, dialect string) (clause.Expression, error) {
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+
+	if cond.Operator == types.FilterNull {
+		if cond.Values[0] == "true" {
+			return clause.Expr{SQL: "? IS NULL", Vars: []interface{}{column}}, nil
+		}
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{column}}, nil
+	}
+
+	if cond.Operator == types.FilterLike {
+		// Without wildcards, like matches values containing the text.
+		pattern := cond.Values[0]
+		if !strings.Contains(pattern, "%") {
+			pattern = "%" + pattern + "%"
+		}
+		if dialect == DriverPostgres && isUUIDSchemaField(field) {
+			// PostgreSQL has no LIKE on its native uuid type
+			return clause.Expr{SQL: "CAST(? AS TEXT) LIKE ?", Vars: []interface{}{column, pattern}}, nil
+		}
+		return clause.Like{Column: column, Value: pattern}, nil
+	}
+
+	values := make([]interface{}, len(cond.Values))
+	for i, raw := range cond.Values {
+		v, err := convertFilterValue(field, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: filter[%s][%s]: %v", types.ErrInvalidQuery, cond.Field, cond.Operator, err)
+		}
+		values[i] = v
+	}
+
+	switch cond.Operator {
+	case types.FilterEq:
+		return clause.Eq{Column: column, Value: values[0]}, nil
+	case types.FilterNe:
+		return clause.Neq{Column: column, Value: values[0]}, nil
+	case types.FilterGt:
+		return clause.Gt{Column: column, Value: values[0]}, nil
+	case types.FilterGte:
+		return clause.Gte{Column: column, Value: values[0]}, nil
+	case types.FilterLt:
+		return clause.Lt{Column: column, Value: values[0]}, nil
+	case types.FilterLte:
+		return clause.Lte{Column: column, Value: values[0]}, nil
+	case types.FilterIn:
+		return clause.IN{Column: column, Values: values}, nil
+	case types.FilterNin:
+		return clause.Not(clause.IN{Column: column, Values: values}), nil
+	case types.FilterBetween:
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{column, values[0], values[1]}}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported filter operator %q", types.ErrInvalidQuery, cond.Operator)
+}
+
+// convertFilterValue parses a raw filter value as the Go type of field.
+func convertFilterValue(field *schema.Field, raw string) (interface{}, error) {
+	t := field.FieldType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if isUUIDSchemaField(field) {
+			if _, err := uuid.Parse(raw); err != nil {
+				return nil, fmt.Errorf("%q is not a UUID", raw)
+			}
+		}
+		return raw, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return n, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a positive integer", raw)
+		}
+		return n, nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return f, nil
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return v, nil
+	}
+
+	// time.Time and wrappers such as gorm.DeletedAt
+	if field.DataType == schema.Time {
+		for _, layout := range filterTimeLayouts {
+			if ts, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+				return ts, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not a time (use RFC3339 or 2006-01-02)", raw)
+	}
+	return raw, nil
+}
+
+// isUUIDSchemaField reports whether field is stored as a UUID by the
+// migration generator, natively on PostgreSQL.
+func isUUIDSchemaField(field *schema.Field) bool {
+	return isUUIDField(field.FieldType, field.Tag.Get("gorm"), field.DBName)
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"study1/internal/core/types"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func TestFilterOperators(t *testing.T) {
+	db := openQueryDB(t)
+
+	tests := []struct {
+		name   string
+		filter url.Values
+		want   []string
+	}{
+		{"eq", url.Values{"filter[age]": {"30"}}, []string{"Budi", "Citra"}},
+		{"explicit eq", url.Values{"filter[age][eq]": {"30"}}, []string{"Budi", "Citra"}},
+		{"ne", url.Values{"filter[age][ne]": {"30"}}, []string{"Andi", "Dewi", "Eka"}},
+		{"gt", url.Values{"filter[age][gt]": {"30"}}, []string{"Dewi"}},
+		{"gte", url.Values{"filter[age][gte]": {"30"}}, []string{"Budi", "Citra", "Dewi"}},
+		{"lt", url.Values{"filter[age][lt]": {"30"}}, []string{"Andi", "Eka"}},
+		{"lte", url.Values{"filter[age][lte]": {"20"}}, []string{"Andi", "Eka"}},
+		{"like contains", url.Values{"filter[name][like]": {"di"}}, []string{"Andi", "Budi"}},
+		{"like wildcard", url.Values{"filter[name][like]": {"%a"}}, []string{"Citra", "Eka"}},
+		{"in", url.Values{"filter[age][in]": {"20,40"}}, []string{"Andi", "Dewi", "Eka"}},
+		{"in one value", url.Values{"filter[age][in]": {"40"}}, []string{"Dewi"}},
+		{"nin", url.Values{"filter[age][nin]": {"20,40"}}, []string{"Budi", "Citra"}},
+		{"between", url.Values{"filter[age][between]": {"25,35"}}, []string{"Budi", "Citra"}},
+		{"between inclusive", url.Values{"filter[age][between]": {"20,30"}}, []string{"Andi", "Budi", "Citra", "Eka"}},
+		{"null", url.Values{"filter[score][null]": {"true"}}, []string{"Budi", "Dewi"}},
+		{"not null", url.Values{"filter[score][null]": {"false"}}, []string{"Andi", "Citra", "Eka"}},
+		{"float skips nulls", url.Values{"filter[score][gt]": {"1.9"}}, []string{"Citra", "Eka"}},
+		{"time", url.Values{"filter[created_at][gte]": {"2026-01-04"}}, []string{"Dewi", "Eka"}},
+		{"combined with and", url.Values{"filter[age]": {"30"}, "filter[score][null]": {"false"}}, []string{"Citra"}},
+		{"column name", url.Values{"filter[Age]": {"40"}}, []string{"Dewi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters, err := types.ParseFilters(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := listNames(db, types.QueryParams{Filter: filters})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterRejectsInvalidQueries(t *testing.T) {
+	db := openQueryDB(t)
+
+	tests := []struct {
+		name   string
+		filter types.FilterCondition
+	}{
+		{"not filterable", types.FilterCondition{Field: "secret", Operator: types.FilterEq, Values: []string{"secret"}}},
+		{"unknown field", types.FilterCondition{Field: "password", Operator: types.FilterEq, Values: []string{"x"}}},
+		{"integer value", types.FilterCondition{Field: "age", Operator: types.FilterEq, Values: []string{"abc"}}},
+		{"integer list value", types.FilterCondition{Field: "age", Operator: types.FilterIn, Values: []string{"20", "old"}}},
+		{"between values", types.FilterCondition{Field: "age", Operator: types.FilterBetween, Values: []string{"a", "b"}}},
+		{"float value", types.FilterCondition{Field: "score", Operator: types.FilterGt, Values: []string{"high"}}},
+		{"time value", types.FilterCondition{Field: "created_at", Operator: types.FilterLt, Values: []string{"yesterday"}}},
+		{"injected condition", types.FilterCondition{Field: "age) OR (1=1", Operator: types.FilterEq, Values: []string{"1"}}},
+		{"injected statement", types.FilterCondition{Field: "name; DROP TABLE query_items; --", Operator: types.FilterEq, Values: []string{"x"}}},
+		{"raw expression", types.FilterCondition{Field: "1=1", Operator: types.FilterEq, Values: []string{"1"}}},
+		{"qualified column", types.FilterCondition{Field: "query_items.secret", Operator: types.FilterEq, Values: []string{"secret"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listNames(db, types.QueryParams{Filter: []types.FilterCondition{tt.filter}})
+			if !errors.Is(err, types.ErrInvalidQuery) {
+				t.Errorf("got %v, %v; want types.ErrInvalidQuery", got, err)
+			}
+		})
+	}
+
+	var count int64
+	if err := db.Model(&queryItem{}).Count(&count).Error; err != nil || count != 5 {
+		t.Errorf("after the rejected filters: %d rows, %v; want 5 rows", count, err)
+	}
+}
+
+type uuidItem struct {
+	ID   uint   `gorm:"primaryKey"`
+	UUID string `gorm:"size:36;column:uuid" filterable:"true"`
+}
+
+// UUID columns are native on PostgreSQL: like needs a cast to text there, and
+// values that are not UUIDs must be rejected rather than failing in the
+// database.
+func TestFilterOnUUIDColumns(t *testing.T) {
+	s, err := schema.Parse(&uuidItem{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	field := s.LookUpField("uuid")
+	pg, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1 user=u dbname=db"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cond    types.FilterCondition
+		dialect string
+		wantSQL string
+	}{
+		{"like on postgres", types.FilterCondition{Field: "uuid", Operator: types.FilterLike, Values: []string{"9f1c"}}, DriverPostgres, `CAST("uuid_items"."uuid" AS TEXT) LIKE $1`},
+		{"like elsewhere", types.FilterCondition{Field: "uuid", Operator: types.FilterLike, Values: []string{"9f1c"}}, DriverSQLite, `"uuid_items"."uuid" LIKE $1`},
+		{"eq", types.FilterCondition{Field: "uuid", Operator: types.FilterEq, Values: []string{"9f1c2d3e-0000-4000-8000-000000000000"}}, DriverPostgres, `"uuid_items"."uuid" = $1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := filterExpression(field, tt.cond, tt.dialect)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stmt := pg.Model(&uuidItem{}).Where(expr).Find(&[]uuidItem{}).Statement
+			if sql := stmt.SQL.String(); !strings.Contains(sql, tt.wantSQL) {
+				t.Errorf("SQL = %s, want it to contain %s", sql, tt.wantSQL)
+			}
+		})
+	}
+
+	for _, value := range []string{"9f1c", "'; DROP TABLE uuid_items; --"} {
+		cond := types.FilterCondition{Field: "uuid", Operator: types.FilterIn, Values: []string{value}}
+		if _, err := filterExpression(field, cond, DriverPostgres); !errors.Is(err, types.ErrInvalidQuery) {
+			t.Errorf("filter[uuid][in]=%q: got %v, want types.ErrInvalidQuery", value, err)
+		}
+	}
+}
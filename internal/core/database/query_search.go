@@ -0,0 +1,206 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchStrategy turns the search parameter into a condition over a model's
+// searchable columns.
+type SearchStrategy interface {
+	// Condition matches rows containing term in any of columns.
+	Condition(columns []string, term string) clause.Expression
+	// Relevance ranks a matching row, higher first, or is nil when the
+	// strategy cannot rank.
+	Relevance(columns []string, term string) clause.Expression
+}
+
+// searchConfig is the Postgres text search configuration used for searches
+// and their indexes. "simple" lower-cases words without stemming, which
+// suits names, e-mail addresses and paths.
+const searchConfig = "simple"
+
+// SearchStrategyFor returns the full-text strategy of a dialect, matching
+// the index the MigrationGenerator creates for it, or LikeSearch where there
+// is none.
+func SearchStrategyFor(dialect string) SearchStrategy {
+	switch dialect {
+	case DriverMySQL:
+		return MySQLFullTextSearch{MinWordLength: 3}
+	case DriverPostgres:
+		return PostgresFullTextSearch{Config: searchConfig}
+	}
+	return LikeSearch{}
+}
+
+// fullTextIndexName names the full-text index over a table's searchable
+// columns. The MigrationGenerator creates the index under this name and
+// searches look it up by it; changing the columns replaces the index.
+func fullTextIndexName(table string, columns []string) string {
+	return fmt.Sprintf("ftx_%s_%s", table, strings.Join(columns, "_"))
+}
+
+var (
+	// fullTextIndexes remembers the full-text indexes found to exist. Missing
+	// ones are looked up again on the next search, so applying the migration
+	// takes effect without a restart.
+	fullTextIndexes sync.Map
+	// missingIndexWarned holds the indexes already reported missing.
+	missingIndexWarned sync.Map
+)
+
+// hasFullTextIndex reports whether the full-text index over a table's
+// searchable columns exists, warning once when it does not.
+func hasFullTextIndex(db *gorm.DB, table string, columns []string) bool {
+	name := fullTextIndexName(table, columns)
+	key := db.Dialector.Name() + ":" + table + "." + name
+	if _, ok := fullTextIndexes.Load(key); ok {
+		return true
+	}
+	if !db.Migrator().HasIndex(table, name) {
+		if _, warned := missingIndexWarned.LoadOrStore(key, true); !warned {
+			log.Printf("⚠️  Full-text index %s on %s is missing, searching with LIKE until its migration is applied", name, table)
+		}
+		return false
+	}
+	fullTextIndexes.Store(key, true)
+	return true
+}
+
+// LikeSearch matches "%term%" in every column. It needs no index, but
+// cannot use one either, and does not rank.
+type LikeSearch struct{}
+
+// Condition implements SearchStrategy.
+func (LikeSearch) Condition(columns []string, term string) clause.Expression {
+	var likes []clause.Expression
+	for _, name := range columns {
+		column := clause.Column{Table: clause.CurrentTable, Name: name}
+		likes = append(likes, clause.Like{Column: column, Value: "%" + term + "%"})
+	}
+	return clause.Or(likes...)
+}
+
+// Relevance implements SearchStrategy.
+func (LikeSearch) Relevance(columns []string, term string) clause.Expression {
+	return nil
+}
+
+// MySQLFullTextSearch uses MATCH ... AGAINST in boolean mode, requiring
+// every word of the term as a word prefix. MySQL needs a FULLTEXT index over
+// exactly columns; QueryBuilder searches with LikeSearch while it is missing.
+// Terms with words shorter than MinWordLength, which InnoDB does not index,
+// fall back to LikeSearch too.
+type MySQLFullTextSearch struct {
+	MinWordLength int
+}
+
+// Condition implements SearchStrategy.
+func (s MySQLFullTextSearch) Condition(columns []string, term string) clause.Expression {
+	query, ok := s.query(term)
+	if !ok {
+		return LikeSearch{}.Condition(columns, term)
+	}
+	return clause.Expr{SQL: s.match(columns), Vars: []interface{}{query}}
+}
+
+// Relevance implements SearchStrategy.
+func (s MySQLFullTextSearch) Relevance(columns []string, term string) clause.Expression {
+	query, ok := s.query(term)
+	if !ok {
+		return nil
+	}
+	return clause.Expr{SQL: s.match(columns), Vars: []interface{}{query}}
+}
+
+func (s MySQLFullTextSearch) match(columns []string) string {
+	return fmt.Sprintf("MATCH (%s) AGAINST (? IN BOOLEAN MODE)", strings.Join(columns, ", "))
+}
+
+// query renders term as "+word* +word*".
+func (s MySQLFullTextSearch) query(term string) (string, bool) {
+	words := searchWords(term)
+	if len(words) == 0 {
+		return "", false
+	}
+	for i, word := range words {
+		if len([]rune(word)) < s.MinWordLength {
+			return "", false
+		}
+		words[i] = "+" + word + "*"
+	}
+	return strings.Join(words, " "), true
+}
+
+// PostgresFullTextSearch matches a tsvector built from columns against a
+// prefix tsquery of the term's words, and ranks with ts_rank. The vector is
+// the same expression the MigrationGenerator indexes with GIN.
+type PostgresFullTextSearch struct {
+	// Config is the text search configuration, e.g. "simple" or "english".
+	Config string
+}
+
+// Condition implements SearchStrategy.
+func (s PostgresFullTextSearch) Condition(columns []string, term string) clause.Expression {
+	query, ok := s.query(term)
+	if !ok {
+		return LikeSearch{}.Condition(columns, term)
+	}
+	sql := fmt.Sprintf("%s @@ to_tsquery('%s', ?)", tsvectorSQL(s.Config, columns), s.Config)
+	return clause.Expr{SQL: sql, Vars: []interface{}{query}}
+}
+
+// Relevance implements SearchStrategy.
+func (s PostgresFullTextSearch) Relevance(columns []string, term string) clause.Expression {
+	query, ok := s.query(term)
+	if !ok {
+		return nil
+	}
+	sql := fmt.Sprintf("ts_rank(%s, to_tsquery('%s', ?))", tsvectorSQL(s.Config, columns), s.Config)
+	return clause.Expr{SQL: sql, Vars: []interface{}{query}}
+}
+
+// query renders term as "word:* & word:*".
+func (s PostgresFullTextSearch) query(term string) (string, bool) {
+	words := searchWords(term)
+	if len(words) == 0 {
+		return "", false
+	}
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & "), true
+}
+
+// tsvectorSeparators are replaced by spaces before the text is parsed.
+// Postgres would otherwise keep "/api/v1/users" as one file token and
+// "a.b@example.com" as one email token, so searching part of a path or
+// e-mail address would not match. searchWords splits terms at the same
+// characters.
+const tsvectorSeparators = "[/@._-]"
+
+// tsvectorSQL is the document searched by PostgresFullTextSearch and indexed
+// by the generated GIN index; the two must stay identical for the index to
+// be used.
+func tsvectorSQL(config string, columns []string) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = fmt.Sprintf("coalesce(%s, '')", column)
+	}
+	text := fmt.Sprintf("regexp_replace(%s, '%s', ' ', 'g')", strings.Join(parts, " || ' ' || "), tsvectorSeparators)
+	return fmt.Sprintf("to_tsvector('%s', %s)", config, text)
+}
+
+// searchWords splits a search term into words of letters and digits,
+// dropping full-text operators along with other punctuation.
+func searchWords(term string) []string {
+	return strings.FieldsFunc(term, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// postgresTokens approximates how to_tsvector('simple', ...) splits the
+// normalised document: separators become spaces and the parser breaks words
+// at anything that is not a letter or digit.
+func postgresTokens(text string) []string {
+	text = regexp.MustCompile(tsvectorSeparators).ReplaceAllString(text, " ")
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func TestPostgresSearchMatchesPartsOfPathsAndEmails(t *testing.T) {
+	search := PostgresFullTextSearch{Config: searchConfig}
+
+	tests := []struct {
+		name     string
+		document string
+		term     string
+		query    string
+	}{
+		{"path segment", "/api/v1/users", "users", "users:*"},
+		{"path prefix", "/api/v1/activity-logs/stats", "activity log", "activity:* & log:*"},
+		{"route pattern", "/api/v1/users/:uuid", "v1 users", "v1:* & users:*"},
+		{"email local part", "andi.pratama@example.com", "pratama", "pratama:*"},
+		{"email domain", "andi.pratama@example.com", "example.com", "example:* & com:*"},
+		{"underscore", "raw_path", "path", "path:*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, ok := search.Condition([]string{"path"}, tt.term).(clause.Expr)
+			if !ok || len(expr.Vars) != 1 || expr.Vars[0] != tt.query {
+				t.Fatalf("Condition(%q) = %#v, want query %q", tt.term, expr, tt.query)
+			}
+
+			tokens := postgresTokens(tt.document)
+			for _, word := range strings.Split(tt.query, " & ") {
+				prefix := strings.TrimSuffix(word, ":*")
+				found := false
+				for _, token := range tokens {
+					found = found || strings.HasPrefix(token, prefix)
+				}
+				if !found {
+					t.Errorf("%q: no token of %v starts with %q", tt.document, tokens, prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestPostgresSearchUsesIndexedDocument(t *testing.T) {
+	columns := []string{"path", "raw_path"}
+	want := "to_tsvector('simple', regexp_replace(coalesce(path, '') || ' ' || coalesce(raw_path, ''), '[/@._-]', ' ', 'g'))"
+	if got := tsvectorSQL(searchConfig, columns); got != want {
+		t.Fatalf("tsvectorSQL = %s\nwant %s", got, want)
+	}
+
+	pg, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1 user=u dbname=db"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewMigrationGenerator(pg, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := g.createFullTextIndexSQL("activity_logs", fullTextIndexDef{Name: "ftx_activity_logs_path_raw_path", Columns: columns})
+	condition := PostgresFullTextSearch{Config: searchConfig}.Condition(columns, "users").(clause.Expr)
+	// The index is only used when the search repeats its expression exactly.
+	if !strings.Contains(index, "USING GIN ("+want+")") || !strings.HasPrefix(condition.SQL, want+" @@ ") {
+		t.Errorf("index %s\nand search %s\ndo not use %s", index, condition.SQL, want)
+	}
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"study1/internal/core/types"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DefaultSorter is implemented by models that declare their default list
+// order, in the same syntax as the sort parameter, e.g. "-created_at".
+// Models without it are ordered by primary key.
+type DefaultSorter interface {
+	DefaultSort() string
+}
+
+// Null placement for a sort term.
+const (
+	nullsDefault = ""
+	nullsFirst   = "nulls_first"
+	nullsLast    = "nulls_last"
+)
+
+// sortTerm is one parsed column of a sort.
+type sortTerm struct {
+	Field *schema.Field
+	Desc  bool
+	Nulls string
+}
+
+// parseSort parses a comma-separated sort such as "-created_at,name". A
+// leading '-' sorts descending; ":nulls_first" or ":nulls_last" places NULLs
+// explicitly. "name desc" is also accepted. Fields are resolved by JSON or
+// column name; when requireTag is set they must be tagged sortable.
+func parseSort(s *schema.Schema, sort string, requireTag bool) ([]sortTerm, error) {
+	var terms []sortTerm
+	for _, item := range strings.Split(sort, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		var term sortTerm
+		switch item[0] {
+		case '-':
+			term.Desc = true
+			item = item[1:]
+		case '+':
+			item = item[1:]
+		}
+
+		if name, nulls, ok := strings.Cut(item, ":"); ok {
+			switch nulls = strings.ToLower(strings.TrimSpace(nulls)); nulls {
+			case nullsFirst, nullsLast:
+				term.Nulls = nulls
+			default:
+				return nil, fmt.Errorf("%w: invalid null ordering %q, use nulls_first or nulls_last", types.ErrInvalidQuery, nulls)
+			}
+			item = name
+		}
+
+		name, direction, _ := strings.Cut(strings.TrimSpace(item), " ")
+		switch strings.ToLower(strings.TrimSpace(direction)) {
+		case "", "asc":
+		case "desc":
+			term.Desc = true
+		default:
+			return nil, fmt.Errorf("%w: invalid sort direction %q, use asc or desc", types.ErrInvalidQuery, direction)
+		}
+
+		if requireTag {
+			field, err := resolveField(s, TagSortable, name, "sort by")
+			if err != nil {
+				return nil, err
+			}
+			term.Field = field
+		} else if term.Field = lookupField(s, name); term.Field == nil {
+			return nil, fmt.Errorf("default sort of %s: unknown field %q", s.Name, name)
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// withTieBreaker appends the primary key unless the sort already uses it, so
+// rows with equal sort values come back in a stable order across pages.
+func withTieBreaker(s *schema.Schema, terms []sortTerm) []sortTerm {
+	pk := s.PrioritizedPrimaryField
+	if pk == nil {
+		return terms
+	}
+	desc := false
+	for _, term := range terms {
+		if term.Field == pk {
+			return terms
+		}
+		desc = term.Desc
+	}
+	return append(terms, sortTerm{Field: pk, Desc: desc})
+}
+
+// orderByClause renders leading expressions and then terms as a single
+// ORDER BY. NULL placement uses a CASE expression because MySQL has no NULLS
+// FIRST/LAST.
+func orderByClause(terms []sortTerm, leading ...clause.Expression) clause.OrderBy {
+	exprs := leading
+	for _, term := range terms {
+		column := clause.Column{Table: clause.CurrentTable, Name: term.Field.DBName}
+		switch term.Nulls {
+		case nullsFirst:
+			exprs = append(exprs, clause.Expr{SQL: "CASE WHEN ? IS NULL THEN 0 ELSE 1 END", Vars: []interface{}{column}})
+		case nullsLast:
+			exprs = append(exprs, clause.Expr{SQL: "CASE WHEN ? IS NULL THEN 1 ELSE 0 END", Vars: []interface{}{column}})
+		}
+		sql := "?"
+		if term.Desc {
+			sql = "? DESC"
+		}
+		exprs = append(exprs, clause.Expr{SQL: sql, Vars: []interface{}{column}})
+	}
+	return clause.OrderBy{Expression: clause.CommaExpression{Exprs: exprs}}
+}
+
+// sortTerms returns the requested sort, or the model's default sort, with
+// the primary key tie-breaker.
+func (b *QueryBuilder[T]) sortTerms() ([]sortTerm, error) {
+	s, err := b.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	var terms []sortTerm
+	if b.Params.Sort != "" {
+		terms, err = parseSort(s, b.Params.Sort, true)
+	} else if sorter, ok := any(&b.Model).(DefaultSorter); ok {
+		terms, err = parseSort(s, sorter.DefaultSort(), false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return withTieBreaker(s, terms), nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"study1/internal/core/types"
+)
+
+// queryItem is the model the QueryBuilder tests list. Score is nullable;
+// Secret carries no tags and may not be used in queries.
+type queryItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id" sortable:"true"`
+	Name      string    `json:"name" filterable:"true" sortable:"true" selectable:"true"`
+	Age       int       `json:"age" filterable:"true" sortable:"true"`
+	Score     *float64  `json:"score" filterable:"true" sortable:"true"`
+	CreatedAt time.Time `json:"created_at" filterable:"true" sortable:"true"`
+	Secret    string    `json:"secret"`
+}
+
+func score(v float64) *float64 { return &v }
+
+// openQueryDB returns a database holding five queryItems with repeated ages
+// and two NULL scores, created a day apart from 2026-01-01.
+func openQueryDB(t *testing.T) *DB {
+	t.Helper()
+	db := openTestDB(t)
+	if err := db.AutoMigrate(&queryItem{}); err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local)
+	items := []queryItem{
+		{Name: "Andi", Age: 20, Score: score(1.5)},
+		{Name: "Budi", Age: 30},
+		{Name: "Citra", Age: 30, Score: score(3)},
+		{Name: "Dewi", Age: 40},
+		{Name: "Eka", Age: 20, Score: score(2)},
+	}
+	for i := range items {
+		items[i].CreatedAt = day.AddDate(0, 0, i)
+		items[i].Secret = "secret"
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &DB{DB: db}
+}
+
+// listNames runs params through a QueryBuilder and returns the names found.
+func listNames(db *DB, params types.QueryParams) ([]string, error) {
+	var items []queryItem
+	if err := NewQueryBuilder[queryItem](db.DB, params).Build().Find(&items).Error; err != nil {
+		return nil, err
+	}
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	return names, nil
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"study1/internal/core/config"
+
+	"gorm.io/gorm"
+)
+
+// Seeder populates tables with development or fixture data.
+type Seeder interface {
+	// Name is used to run a single seeder, e.g. `migrate seed users`.
+	Name() string
+	// Models returns the models the seeder writes; their tables must exist
+	// before it runs.
+	Models() []interface{}
+	Run(db *gorm.DB) error
+}
+
+// ErrSeedingInProduction is returned when seeders are run in production.
+var ErrSeedingInProduction = errors.New("seeders must not run in production")
+
+var seeders []Seeder
+
+// RegisterSeeder registers a seeder. Seeders run in registration order.
+func RegisterSeeder(seeder Seeder) {
+	seeders = append(seeders, seeder)
+}
+
+// GetSeeders returns all registered seeders in registration order
+func GetSeeders() []Seeder {
+	return seeders
+}
+
+// GetSeederByName returns a seeder by name
+func GetSeederByName(name string) Seeder {
+	for _, seeder := range seeders {
+		if seeder.Name() == name {
+			return seeder
+		}
+	}
+	return nil
+}
+
+// RunSeeders runs the named seeders, or all of them when no names are given.
+// It refuses to run when env is a production environment.
+func RunSeeders(db *gorm.DB, env string, names ...string) error {
+	if config.IsProduction(env) {
+		return ErrSeedingInProduction
+	}
+
+	toRun := seeders
+	if len(names) > 0 {
+		toRun = nil
+		for _, name := range names {
+			seeder := GetSeederByName(name)
+			if seeder == nil {
+				return fmt.Errorf("seeder %q not found", name)
+			}
+			toRun = append(toRun, seeder)
+		}
+	}
+
+	// Check every table up front so a missing one does not leave the
+	// seeders before it applied.
+	for _, seeder := range toRun {
+		for _, model := range seeder.Models() {
+			if !db.Migrator().HasTable(model) {
+				return fmt.Errorf("seeder %s: table for %T does not exist, run migrations first", seeder.Name(), model)
+			}
+		}
+	}
+
+	for _, seeder := range toRun {
+		log.Printf("Seeding: %s", seeder.Name())
+		if err := seeder.Run(db); err != nil {
+			return fmt.Errorf("seeder %s: %w", seeder.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type seededThing struct{ ID uint }
+
+type unmigratedThing struct{ ID uint }
+
+type testSeeder struct {
+	name   string
+	models []interface{}
+	ran    *bool
+}
+
+func (s testSeeder) Name() string          { return s.name }
+func (s testSeeder) Models() []interface{} { return s.models }
+func (s testSeeder) Run(db *gorm.DB) error { *s.ran = true; return nil }
+
+func TestRunSeedersChecksSelectedTables(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.AutoMigrate(&seededThing{}); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := seeders
+	t.Cleanup(func() { seeders = saved })
+	var thingsRan, othersRan bool
+	seeders = []Seeder{
+		testSeeder{name: "things", models: []interface{}{&seededThing{}}, ran: &thingsRan},
+		testSeeder{name: "others", models: []interface{}{&unmigratedThing{}}, ran: &othersRan},
+	}
+
+	if err := RunSeeders(db, "development", "things"); err != nil {
+		t.Fatalf("seeding things: %v", err)
+	}
+	if !thingsRan {
+		t.Error("things seeder did not run")
+	}
+
+	thingsRan = false
+	if err := RunSeeders(db, "development"); err == nil {
+		t.Fatal("seeding all: want an error for the missing table")
+	}
+	if thingsRan || othersRan {
+		t.Error("seeders ran although a table was missing")
+	}
+}
+
+func TestRunSeedersRefusesProduction(t *testing.T) {
+	for _, env := range []string{"production", "Production", " PROD "} {
+		if err := RunSeeders(nil, env); !errors.Is(err, ErrSeedingInProduction) {
+			t.Errorf("RunSeeders(%q) = %v, want ErrSeedingInProduction", env, err)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// WithTransaction runs fn in a transaction on db, committing when it returns
+// nil and rolling back when it returns an error or panics. Repositories built
+// on tx with their usual constructors take part in the transaction, so work
+// across modules commits or fails as a whole. Calling WithTransaction with tx
+// again nests through a savepoint. Queries on tx carry ctx, so they are
+// cancelled with the request.
+func WithTransaction(ctx context.Context, db *DB, fn func(tx *DB) error) error {
+	return db.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&DB{DB: tx})
+	})
+}
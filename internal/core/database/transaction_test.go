@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type txThing struct {
+	ID   uint
+	Name string
+}
+
+func TestWithTransactionRollsBackOnError(t *testing.T) {
+	db := &DB{DB: openTestDB(t)}
+	if err := db.AutoMigrate(&txThing{}); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("third write failed")
+	err := WithTransaction(context.Background(), db, func(tx *DB) error {
+		for _, name := range []string{"first", "second"} {
+			if err := tx.Create(&txThing{Name: name}).Error; err != nil {
+				return err
+			}
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("WithTransaction = %v, want %v", err, failed)
+	}
+
+	var count int64
+	if err := db.Model(&txThing{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("%d rows kept after rollback, want 0", count)
+	}
+}
+
+func TestWithTransactionUsesContext(t *testing.T) {
+	db := &DB{DB: openTestDB(t)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := WithTransaction(ctx, db, func(tx *DB) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WithTransaction on a cancelled context = %v, want context.Canceled", err)
+	}
+}
@@ -1,27 +1,58 @@
 package middleware
 
 import (
+	"strings"
 	"time"
 
-	"study1/internal/core/database"
-	"study1/internal/modules/activity"
-
 	"github.com/gin-gonic/gin"
 )
 
-// ActivityLogger returns a Gin middleware that records HTTP request/response
-// information into the database's activity_logs table.
-func ActivityLogger(db *database.DB) gin.HandlerFunc {
+// RequestInfo describes a handled HTTP request for activity logging.
+type RequestInfo struct {
+	RequestID string
+	Method    string
+	// Path is the matched route pattern, empty when no route matched.
+	Path string
+	// RawPath is the path as requested. Query is the query string, with
+	// the fields in ActivityOptions.RedactFields redacted.
+	RawPath      string
+	Query        string
+	Status       int
+	ResponseSize int
+	LatencyMs    int64
+	IP           string
+	UserAgent    string
+	UserID       *uint
+	// Errors joins the errors handlers attached with c.Error.
+	Errors string
+	// RequestBody and ResponseBody are only set on routes using CaptureBody,
+	// with the fields in ActivityOptions.RedactFields redacted.
+	RequestBody  string
+	ResponseBody string
+}
+
+// ActivityOptions configures ActivityLogger.
+type ActivityOptions struct {
+	// RedactFields lists body fields and query parameters
+	// (case-insensitive) whose values are replaced before they are
+	// recorded, e.g. password, email.
+	RedactFields []string
+}
+
+// ActivityLogger returns a Gin middleware that passes information about every
+// handled request to record. record runs on the request goroutine after the
+// response is written, so it must not block (e.g. hand off to a buffer).
+func ActivityLogger(opts ActivityOptions, record func(info RequestInfo)) gin.HandlerFunc {
+	redact := make(map[string]bool, len(opts.RedactFields))
+	for _, field := range opts.RedactFields {
+		redact[strings.ToLower(strings.TrimSpace(field))] = true
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		// Process request
 		c.Next()
 
-		latency := time.Since(start).Milliseconds()
-		status := c.Writer.Status()
-		ip := c.ClientIP()
-		ua := c.Request.UserAgent()
-
 		// Try to get user id from context if present (modules can set it)
 		var uid *uint
 		if v, ok := c.Get("userID"); ok {
@@ -30,17 +61,29 @@ func ActivityLogger(db *database.DB) gin.HandlerFunc {
 			}
 		}
 
-		entry := activity.ActivityLog{
-			Method:    c.Request.Method,
-			Path:      c.FullPath(),
-			Status:    status,
-			LatencyMs: latency,
-			IP:        ip,
-			UserAgent: ua,
-			UserID:    uid,
+		info := RequestInfo{
+			RequestID:    c.GetString(RequestIDKey),
+			Method:       c.Request.Method,
+			Path:         c.FullPath(),
+			RawPath:      c.Request.URL.Path,
+			Query:        redactQuery(c.Request.URL.RawQuery, redact),
+			Status:       c.Writer.Status(),
+			ResponseSize: max(c.Writer.Size(), 0),
+			LatencyMs:    time.Since(start).Milliseconds(),
+			IP:           c.ClientIP(),
+			UserAgent:    c.Request.UserAgent(),
+			UserID:       uid,
+		}
+		if len(c.Errors) > 0 {
+			info.Errors = strings.Join(c.Errors.Errors(), "; ")
+		}
+		if body, ok := c.Get(requestBodyKey); ok {
+			info.RequestBody = redactBody(body.(capturedBody), redact)
+		}
+		if body, ok := c.Get(responseBodyKey); ok {
+			info.ResponseBody = redactBody(body.(capturedBody), redact)
 		}
 
-		// Best-effort insert; do not break request on error
-		_ = db.Create(&entry).Error
+		record(info)
 	}
 }
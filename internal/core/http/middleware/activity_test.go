@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func serveLogged(t *testing.T, req *http.Request, handlers ...gin.HandlerFunc) RequestInfo {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	var info RequestInfo
+	router := gin.New()
+	router.Use(ActivityLogger(ActivityOptions{RedactFields: []string{"password", "email", "token", "api_key"}}, func(i RequestInfo) { info = i }))
+	router.Any("/things", handlers...)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	return info
+}
+
+func TestActivityLoggerRedactsQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  url.Values
+	}{
+		{"plain parameter", "token=abc&page=2", url.Values{"token": {redactedValue}, "page": {"2"}}},
+		{"case-insensitive", "API_KEY=abc", url.Values{"API_KEY": {redactedValue}}},
+		{"bracketed field", "filter[email][eq]=a@example.com&filter[age][gte]=18", url.Values{"filter[email][eq]": {redactedValue}, "filter[age][gte]": {"18"}}},
+		{"repeated parameter", "token=a&token=b", url.Values{"token": {redactedValue}}},
+		{"no sensitive parameter", "sort=-age", url.Values{"sort": {"-age"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := serveLogged(t, httptest.NewRequest(http.MethodGet, "/things?"+tt.query, nil), func(c *gin.Context) {})
+			if want := tt.want.Encode(); info.Query != want {
+				t.Errorf("Query = %q, want %q", info.Query, want)
+			}
+		})
+	}
+
+	info := serveLogged(t, httptest.NewRequest(http.MethodGet, "/things?token=%zz", nil), func(c *gin.Context) {})
+	if strings.Contains(info.Query, "token") {
+		t.Errorf("unparsable query recorded: %q", info.Query)
+	}
+}
+
+func TestCaptureBodyBoundsWhatItReads(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		captured bool
+	}{
+		{"small body", 100, true},
+		{"body at the cap", MaxCapturedBodyBytes, true},
+		{"body over the cap", 4 * MaxCapturedBodyBytes, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A JSON string of tt.size bytes.
+			body := `"` + strings.Repeat("a", tt.size-2) + `"`
+			var handled []byte
+			req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			info := serveLogged(t, req, CaptureBody(), func(c *gin.Context) {
+				var err error
+				if handled, err = io.ReadAll(c.Request.Body); err != nil {
+					t.Error(err)
+				}
+			})
+
+			if !bytes.Equal(handled, []byte(body)) {
+				t.Errorf("handler read %d bytes, want all %d", len(handled), len(body))
+			}
+			if got := info.RequestBody == body; got != tt.captured {
+				t.Errorf("body recorded = %v, want %v (got %.40q)", got, tt.captured, info.RequestBody)
+			}
+		})
+	}
+}
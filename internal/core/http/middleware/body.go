@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// MaxCapturedBodyBytes caps how much of a request or response body is kept.
+	MaxCapturedBodyBytes = 64 << 10
+
+	requestBodyKey  = "activity.requestBody"
+	responseBodyKey = "activity.responseBody"
+
+	redactedValue = "[REDACTED]"
+)
+
+// CaptureBody opts a route into recording its request and response bodies in
+// the activity log, e.g. g.POST("", middleware.CaptureBody(), h.CreateOnes).
+// ActivityLogger redacts the bodies before they are recorded.
+func CaptureBody() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body != nil {
+			// Read one byte past the cap to tell a truncated body from a
+			// full one, without buffering bodies of any size in memory.
+			original := c.Request.Body
+			prefix, err := io.ReadAll(io.LimitReader(original, MaxCapturedBodyBytes+1))
+			// Hand the handler the full body again: the prefix read here
+			// followed by the rest, including after a read error.
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(prefix), original), original}
+			if err == nil {
+				c.Set(requestBodyKey, capturedBody{
+					contentType: c.ContentType(),
+					data:        truncate(prefix),
+					truncated:   len(prefix) > MaxCapturedBodyBytes,
+				})
+			}
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		c.Set(responseBodyKey, capturedBody{
+			contentType: writer.Header().Get("Content-Type"),
+			data:        writer.buf.Bytes(),
+			truncated:   writer.truncated,
+		})
+	}
+}
+
+// readCloser reads from Reader and closes Closer, the original request body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type capturedBody struct {
+	contentType string
+	data        []byte
+	truncated   bool
+}
+
+// bodyCaptureWriter keeps a copy of the first MaxCapturedBodyBytes written.
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyCaptureWriter) capture(b []byte) {
+	room := MaxCapturedBodyBytes - w.buf.Len()
+	if len(b) > room {
+		b = b[:room]
+		w.truncated = true
+	}
+	w.buf.Write(b)
+}
+
+func truncate(b []byte) []byte {
+	if len(b) > MaxCapturedBodyBytes {
+		return b[:MaxCapturedBodyBytes]
+	}
+	return b
+}
+
+// redactBody returns the body with the values of the given fields replaced.
+// Only JSON and form bodies can be redacted; anything else, including
+// truncated JSON, is omitted rather than recorded unredacted.
+func redactBody(body capturedBody, fields map[string]bool) string {
+	if len(body.data) == 0 {
+		return ""
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(body.contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var v interface{}
+		if body.truncated || json.Unmarshal(body.data, &v) != nil {
+			break
+		}
+		redacted, err := json.Marshal(redactJSON(v, fields))
+		if err != nil {
+			break
+		}
+		return string(redacted)
+
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body.data))
+		if body.truncated || err != nil {
+			break
+		}
+		return redactValues(values, fields).Encode()
+	}
+
+	return fmt.Sprintf("[%d bytes of %s omitted]", len(body.data), mediaTypeOrUnknown(mediaType))
+}
+
+// redactQuery returns the query string with the values of the given fields
+// replaced. A query that cannot be parsed is omitted.
+func redactQuery(rawQuery string, fields map[string]bool) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return fmt.Sprintf("[%d bytes of unparsable query omitted]", len(rawQuery))
+	}
+	return redactValues(values, fields).Encode()
+}
+
+// redactValues replaces the values of keys naming one of the fields, either
+// as the whole key or as a bracketed part of it, e.g. filter[email][eq].
+func redactValues(values url.Values, fields map[string]bool) url.Values {
+	for key := range values {
+		if redactsKey(key, fields) {
+			values[key] = []string{redactedValue}
+		}
+	}
+	return values
+}
+
+func redactsKey(key string, fields map[string]bool) bool {
+	for _, part := range strings.FieldsFunc(strings.ToLower(key), func(r rune) bool { return r == '[' || r == ']' }) {
+		if fields[part] {
+			return true
+		}
+	}
+	return false
+}
+
+func redactJSON(v interface{}, fields map[string]bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, child := range val {
+			if fields[strings.ToLower(key)] {
+				val[key] = redactedValue
+				continue
+			}
+			val[key] = redactJSON(child, fields)
+		}
+	case []interface{}:
+		for i, child := range val {
+			val[i] = redactJSON(child, fields)
+		}
+	}
+	return v
+}
+
+func mediaTypeOrUnknown(mediaType string) string {
+	if mediaType == "" {
+		return "unknown type"
+	}
+	return mediaType
+}
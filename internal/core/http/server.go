@@ -1,10 +1,12 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"net/http"
+
 	"study1/internal/core/config"
-	"study1/internal/core/database"
-	httpmw "study1/internal/core/http/middleware"
+	"study1/internal/core/http/middleware"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -12,8 +14,9 @@ import (
 )
 
 type Server struct {
-	router *gin.Engine
-	config *config.Config
+	router     *gin.Engine
+	config     *config.Config
+	httpServer *http.Server
 }
 
 // Interface untuk module yang bisa register routes
@@ -21,13 +24,40 @@ type RouteRegistrar interface {
 	RegisterRoutes(router *gin.RouterGroup)
 }
 
-// NewServer creates a new HTTP server and registers provided modules. Accepts
-// a database handle so middleware (e.g., activity logging) can write logs.
-func NewServer(cfg *config.Config, db *database.DB, modules ...RouteRegistrar) *Server {
-	router := gin.Default()
+// MiddlewareProvider is implemented by modules that add middleware to every
+// route, such as activity logging.
+type MiddlewareProvider interface {
+	Middleware() []gin.HandlerFunc
+}
+
+// HealthReporter reports the health of every module by name for the /health
+// endpoint; a nil error means the module is healthy. module.Registry
+// implements it.
+type HealthReporter interface {
+	Health(ctx context.Context) map[string]error
+}
+
+// ShutdownListener is implemented by modules with long-lived responses, such
+// as event streams, that must end when shutdown starts so in-flight requests
+// can drain.
+type ShutdownListener interface {
+	OnShutdown()
+}
 
-	// Middleware: include standard logger/recovery and activity DB logger
-	router.Use(gin.Logger(), gin.Recovery(), httpmw.ActivityLogger(db))
+// NewServer creates a new HTTP server and registers provided modules.
+// Modules implementing MiddlewareProvider add middleware to every route;
+// /health reports health.
+func NewServer(cfg *config.Config, health HealthReporter, modules ...RouteRegistrar) *Server {
+	router := gin.New()
+
+	// Middleware: standard logger/recovery (gin.Default would add them a
+	// second time), request IDs and module middleware (e.g. activity logging)
+	router.Use(gin.Logger(), gin.Recovery(), middleware.RequestID())
+	for _, module := range modules {
+		if provider, ok := module.(MiddlewareProvider); ok {
+			router.Use(provider.Middleware()...)
+		}
+	}
 
 	// Swagger
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -37,7 +67,7 @@ func NewServer(cfg *config.Config, db *database.DB, modules ...RouteRegistrar) *
 	{
 		api.GET("/", apiRoot(cfg))
 		api.GET("/info", apiInfo(cfg))
-		api.GET("/health", apiHealth(cfg))
+		api.GET("/health", apiHealth(cfg, health))
 
 		// Register semua modules
 		for _, module := range modules {
@@ -45,11 +75,35 @@ func NewServer(cfg *config.Config, db *database.DB, modules ...RouteRegistrar) *
 		}
 	}
 
-	return &Server{router: router, config: cfg}
+	httpServer := &http.Server{
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+	for _, module := range modules {
+		if listener, ok := module.(ShutdownListener); ok {
+			httpServer.RegisterOnShutdown(listener.OnShutdown)
+		}
+	}
+
+	return &Server{router: router, config: cfg, httpServer: httpServer}
 }
 
+// Start serves HTTP on port until Shutdown is called, in which case it
+// returns nil.
 func (s *Server) Start(port string) error {
-	return s.router.Run(":" + port)
+	s.httpServer.Addr = ":" + port
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
 }
 
 func (s *Server) GetRouter() *gin.Engine {
@@ -96,12 +150,26 @@ func apiRoot(cfg *config.Config) gin.HandlerFunc {
 // @Tags general
 // @Produce json
 // @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
 // @Router /health [get]
-func apiHealth(cfg *config.Config) gin.HandlerFunc {
+func apiHealth(cfg *config.Config, health HealthReporter) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":      "OK",
+		status, code := "OK", http.StatusOK
+		results := health.Health(c.Request.Context())
+		modules := make(map[string]string, len(results))
+		for name, err := range results {
+			if err != nil {
+				modules[name] = err.Error()
+				status, code = "DEGRADED", http.StatusServiceUnavailable
+				continue
+			}
+			modules[name] = "OK"
+		}
+
+		c.JSON(code, gin.H{
+			"status":      status,
 			"environment": cfg.Server.Environtment,
+			"modules":     modules,
 		})
 	}
 }
@@ -0,0 +1,19 @@
+package http
+
+import (
+	"testing"
+
+	"study1/internal/core/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewServerRegistersMiddlewareOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := NewServer(&config.Config{}, nil)
+
+	// Logger, Recovery and RequestID, once each.
+	if got := len(s.GetRouter().Handlers); got != 3 {
+		t.Errorf("router has %d global handlers, want 3", got)
+	}
+}
@@ -0,0 +1,95 @@
+package module
+
+import (
+	"context"
+	"fmt"
+
+	"study1/internal/core/config"
+	"study1/internal/core/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Module is implemented by every application module. The app builds modules
+// from the registered factories, starts them in dependency order and mounts
+// their routes, so adding a module does not require editing app.go or the
+// HTTP server.
+type Module interface {
+	// Name must be unique; other modules refer to it in Dependencies.
+	Name() string
+	// Dependencies lists the names of modules that must start first.
+	Dependencies() []string
+	// Models returns the models whose tables the module owns.
+	Models() []interface{}
+	// Migrations returns the registered migrations for the module's tables
+	// on the connected database's dialect.
+	Migrations() []*database.Migration
+	RegisterRoutes(router *gin.RouterGroup)
+	// Start runs after every dependency has started.
+	Start(ctx context.Context) error
+	// Stop runs in reverse start order when the app shuts down.
+	Stop(ctx context.Context) error
+	// Health reports whether the module can serve requests.
+	Health(ctx context.Context) error
+}
+
+// Base provides the model, migration, health and no-op lifecycle methods of
+// Module. Modules embed it and override what they need.
+type Base struct {
+	ModuleName string
+	DB         *database.DB
+}
+
+// Name implements Module.
+func (b Base) Name() string { return b.ModuleName }
+
+// Dependencies implements Module.
+func (Base) Dependencies() []string { return nil }
+
+// Models implements Module.
+func (b Base) Models() []interface{} { return database.ModelsOf(b.ModuleName) }
+
+// Migrations implements Module.
+func (b Base) Migrations() []*database.Migration {
+	if b.DB == nil {
+		return nil
+	}
+	return database.MigrationsOf(b.ModuleName, b.DB.Dialector.Name())
+}
+
+// Start implements Module.
+func (Base) Start(ctx context.Context) error { return nil }
+
+// Stop implements Module.
+func (Base) Stop(ctx context.Context) error { return nil }
+
+// Health implements Module by checking that the module's tables exist.
+func (b Base) Health(ctx context.Context) error {
+	if b.DB == nil {
+		return nil
+	}
+	migrator := b.DB.WithContext(ctx).Migrator()
+	for _, model := range b.Models() {
+		if !migrator.HasTable(model) {
+			return fmt.Errorf("table for %T is missing", model)
+		}
+	}
+	return nil
+}
+
+// Factory builds a module from the app configuration and the shared
+// database handle.
+type Factory func(cfg *config.Config, db *database.DB) Module
+
+var factories []Factory
+
+// Register registers a module factory, usually from the module's init
+// function. Factories are called in registration order by the app.
+func Register(factory Factory) {
+	factories = append(factories, factory)
+}
+
+// Factories returns all registered module factories
+func Factories() []Factory {
+	return factories
+}
@@ -0,0 +1,130 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Registry holds the modules of an app and resolves the order they start in.
+type Registry struct {
+	modules map[string]Module
+	added   []Module
+	order   []Module
+	started []Module
+}
+
+// NewRegistry creates an empty module registry
+func NewRegistry() *Registry {
+	return &Registry{modules: make(map[string]Module)}
+}
+
+// Add adds a module. Names must be unique.
+func (r *Registry) Add(m Module) error {
+	if _, exists := r.modules[m.Name()]; exists {
+		return fmt.Errorf("module %s registered twice", m.Name())
+	}
+	r.modules[m.Name()] = m
+	r.added = append(r.added, m)
+	r.order = nil
+	return nil
+}
+
+// Resolve orders the modules so every module comes after its dependencies.
+// Modules without an ordering constraint keep the order they were added in.
+// It fails on unknown dependencies and dependency cycles.
+func (r *Registry) Resolve() ([]Module, error) {
+	if r.order != nil {
+		return r.order, nil
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(r.added))
+	order := make([]Module, 0, len(r.added))
+
+	var visit func(m Module, path []string) error
+	visit = func(m Module, path []string) error {
+		switch state[m.Name()] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("module dependency cycle: %s", strings.Join(append(path, m.Name()), " -> "))
+		}
+
+		state[m.Name()] = visiting
+		for _, dep := range m.Dependencies() {
+			depModule, ok := r.modules[dep]
+			if !ok {
+				return fmt.Errorf("module %s depends on unknown module %s", m.Name(), dep)
+			}
+			if err := visit(depModule, append(path, m.Name())); err != nil {
+				return err
+			}
+		}
+		state[m.Name()] = done
+		order = append(order, m)
+		return nil
+	}
+
+	for _, m := range r.added {
+		if err := visit(m, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	r.order = order
+	return order, nil
+}
+
+// Start starts the modules in dependency order. If a module fails to start,
+// the modules already started are stopped again.
+func (r *Registry) Start(ctx context.Context) error {
+	order, err := r.Resolve()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range order {
+		if err := m.Start(ctx); err != nil {
+			if stopErr := r.Stop(ctx); stopErr != nil {
+				log.Printf("⚠️  Failed to stop modules: %v", stopErr)
+			}
+			return fmt.Errorf("start module %s: %w", m.Name(), err)
+		}
+		r.started = append(r.started, m)
+		log.Printf("✅ Module started: %s", m.Name())
+	}
+	return nil
+}
+
+// Stop stops the started modules in reverse start order. Every module is
+// stopped even if an earlier one fails; the errors are joined.
+func (r *Registry) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(r.started) - 1; i >= 0; i-- {
+		m := r.started[i]
+		if err := m.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop module %s: %w", m.Name(), err))
+			continue
+		}
+		log.Printf("Module stopped: %s", m.Name())
+	}
+	r.started = nil
+	return errors.Join(errs...)
+}
+
+// Health runs every module's health check and returns the result by name.
+// A nil error means the module is healthy.
+func (r *Registry) Health(ctx context.Context) map[string]error {
+	results := make(map[string]error, len(r.added))
+	for _, m := range r.added {
+		results[m.Name()] = m.Health(ctx)
+	}
+	return results
+}
@@ -0,0 +1,42 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type healthModule struct {
+	Base
+	err error
+}
+
+func (m healthModule) RegisterRoutes(router *gin.RouterGroup) {}
+
+func (m healthModule) Health(ctx context.Context) error { return m.err }
+
+func TestRegistryHealth(t *testing.T) {
+	down := errors.New("table for *user.User is missing")
+	r := NewRegistry()
+	for _, m := range []Module{
+		healthModule{Base: Base{ModuleName: "user"}, err: down},
+		healthModule{Base: Base{ModuleName: "activity"}},
+	} {
+		if err := r.Add(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results := r.Health(context.Background())
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %v", len(results), results)
+	}
+	if err, ok := results["activity"]; !ok || err != nil {
+		t.Errorf("activity = %v, %v; want healthy", err, ok)
+	}
+	if err := results["user"]; !errors.Is(err, down) {
+		t.Errorf("user = %v, want %v", err, down)
+	}
+}
@@ -5,6 +5,8 @@ import (
 
 	"study1/internal/core/database"
 	"study1/internal/core/types"
+
+	"gorm.io/gorm/clause"
 )
 
 type GenericRepository[T any] struct {
@@ -13,6 +15,8 @@ type GenericRepository[T any] struct {
 }
 
 // NewGenericRepository creates a repository without soft-delete behavior.
+// Repositories created on a transaction from database.WithTransaction run
+// every operation in it.
 func NewGenericRepository[T any](db *database.DB) *GenericRepository[T] {
 	return &GenericRepository[T]{db: db, softDelete: false}
 }
@@ -24,43 +28,60 @@ func NewGenericRepositoryWithSoftDelete[T any](db *database.DB, softDelete bool)
 
 func (r *GenericRepository[T]) FindManys(params types.QueryParams) ([]T, *types.Meta, error) {
 	var models []T
-	var total int64
 
 	// Ensure pagination values are set
 	params.SetDefaultPagination()
 
-	// Count total records (before pagination)
-	var countModel T
-	countQuery := r.db.Model(&countModel)
-	if r.softDelete {
-		countQuery = countQuery.Where("deleted_at IS NULL")
+	builder := r.queryBuilder(params)
+	if params.UsesCursor() {
+		return r.findManysByCursor(builder)
 	}
-	if err := countQuery.Count(&total).Error; err != nil {
-		return nil, nil, err
+
+	meta := &types.Meta{
+		Page:     params.Page,
+		PageSize: params.PageSize,
 	}
 
-	// Build query with all conditions
-	query := database.NewQueryBuilder[T](r.db.DB, params).Build()
-	if r.softDelete {
-		query = query.Where("deleted_at IS NULL")
+	// Count matching records (before pagination)
+	if !params.SkipCount {
+		total, err := builder.Count()
+		if err != nil {
+			return nil, nil, err
+		}
+		count := int(total)
+		meta.Total = &count
 	}
 
-	// Execute query with pagination
-	offset := (params.Page - 1) * params.PageSize
-	if err := query.Offset(offset).Limit(params.PageSize).Find(&models).Error; err != nil {
+	// Build query with all conditions and pagination
+	if err := builder.Build().Find(&models).Error; err != nil {
 		return nil, nil, err
 	}
 
 	// Calculate total pages
-	pages := (int(total) + params.PageSize - 1) / params.PageSize
+	meta.CalculatePages()
 
-	meta := &types.Meta{
-		Page:     params.Page,
-		PageSize: params.PageSize,
-		Total:    int(total),
-		Pages:    pages,
+	return models, meta, nil
+}
+
+// queryBuilder returns a builder for params that also excludes soft-deleted
+// rows, from both the page and the count.
+func (r *GenericRepository[T]) queryBuilder(params types.QueryParams) *database.QueryBuilder[T] {
+	builder := database.NewQueryBuilder[T](r.db.DB, params)
+	if r.softDelete {
+		builder.Where(clause.Expr{SQL: "? IS NULL", Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: "deleted_at"}}})
+	}
+	return builder
+}
+
+// findManysByCursor reads one keyset page. It never counts or offsets, so
+// the cost stays the same however deep the client scrolls.
+func (r *GenericRepository[T]) findManysByCursor(builder *database.QueryBuilder[T]) ([]T, *types.Meta, error) {
+	var models []T
+	if err := builder.BuildKeyset().Find(&models).Error; err != nil {
+		return nil, nil, err
 	}
 
+	models, meta := builder.KeysetPage(models)
 	return models, meta, nil
 }
 
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// FieldList returns the JSON keys a sparse response keeps: the fields
+// parameter plus any included relations, or nil when fields is not set and
+// responses are complete.
+func (q *QueryParams) FieldList() []string {
+	if strings.TrimSpace(q.Fields) == "" {
+		return nil
+	}
+	var keys []string
+	seen := make(map[string]bool)
+	for _, list := range []string{q.Fields, q.Include} {
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" && !seen[name] {
+				seen[name] = true
+				keys = append(keys, name)
+			}
+		}
+	}
+	return keys
+}
+
+// SparseObject is a JSON object holding some keys of a response, written in
+// the order they were requested.
+type SparseObject struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o SparseObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	written := 0
+	for _, key := range o.keys {
+		value, ok := o.values[key]
+		if !ok {
+			continue
+		}
+		if written > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+		written++
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// SelectFields serializes each item and keeps only the given keys, so
+// fields that were not selected are left out rather than sent as zero
+// values. Keys an item omits, e.g. through omitempty, stay omitted.
+func SelectFields[T any](items []T, keys []string) ([]SparseObject, error) {
+	out := make([]SparseObject, len(items))
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+		out[i] = SparseObject{keys: keys, values: values}
+	}
+	return out, nil
+}
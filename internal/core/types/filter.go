@@ -0,0 +1,151 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidQuery is returned for list queries with invalid filters, sorting
+// or fields; handlers report it as 400 Bad Request.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// FilterOperator is a comparison in a filter condition.
+type FilterOperator string
+
+const (
+	FilterEq      FilterOperator = "eq"
+	FilterNe      FilterOperator = "ne"
+	FilterGt      FilterOperator = "gt"
+	FilterGte     FilterOperator = "gte"
+	FilterLt      FilterOperator = "lt"
+	FilterLte     FilterOperator = "lte"
+	FilterLike    FilterOperator = "like"
+	FilterIn      FilterOperator = "in"
+	FilterNin     FilterOperator = "nin"
+	FilterBetween FilterOperator = "between"
+	FilterNull    FilterOperator = "null"
+)
+
+var filterOperators = map[FilterOperator]bool{
+	FilterEq: true, FilterNe: true, FilterGt: true, FilterGte: true, FilterLt: true, FilterLte: true,
+	FilterLike: true, FilterIn: true, FilterNin: true, FilterBetween: true, FilterNull: true,
+}
+
+// FilterCondition is one parsed filter, e.g. filter[age][gte]=18. Conditions
+// in a query are combined with AND.
+type FilterCondition struct {
+	Field    string
+	Operator FilterOperator
+	// Values holds the raw values: one for most operators, one or more for
+	// in/nin, exactly two for between. For null it is "true" (IS NULL) or
+	// "false" (IS NOT NULL). Values are converted to the column's type when
+	// the query is built.
+	Values []string
+}
+
+// ParseFilters parses filter[field]=value (eq) and filter[field][op]=value
+// parameters. in and nin take comma-separated values and between takes
+// "min,max".
+func ParseFilters(values url.Values) ([]FilterCondition, error) {
+	// Sort keys so conditions, and errors, come out in a stable order.
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var conditions []FilterCondition
+	for _, key := range keys {
+		field, op, err := parseFilterKey(key)
+		if err != nil {
+			return nil, err
+		}
+		for _, raw := range values[key] {
+			cond, err := newFilterCondition(field, op, raw)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, cond)
+		}
+	}
+	return conditions, nil
+}
+
+// BindFilters parses the filter parameters of a request query into q.Filter.
+func (q *QueryParams) BindFilters(values url.Values) error {
+	filters, err := ParseFilters(values)
+	if err != nil {
+		return err
+	}
+	q.Filter = filters
+	return nil
+}
+
+// parseFilterKey splits filter[field] or filter[field][op].
+func parseFilterKey(key string) (string, FilterOperator, error) {
+	rest := strings.TrimPrefix(key, "filter")
+	var parts []string
+	for rest != "" {
+		if rest[0] != '[' {
+			return "", "", fmt.Errorf("%w: malformed filter %q", ErrInvalidQuery, key)
+		}
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			return "", "", fmt.Errorf("%w: malformed filter %q", ErrInvalidQuery, key)
+		}
+		parts = append(parts, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	if len(parts) == 0 || len(parts) > 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("%w: malformed filter %q, expected filter[field] or filter[field][op]", ErrInvalidQuery, key)
+	}
+	op := FilterEq
+	if len(parts) == 2 {
+		op = FilterOperator(strings.ToLower(parts[1]))
+		if !filterOperators[op] {
+			return "", "", fmt.Errorf("%w: unknown filter operator %q in %q", ErrInvalidQuery, parts[1], key)
+		}
+	}
+	return parts[0], op, nil
+}
+
+func newFilterCondition(field string, op FilterOperator, raw string) (FilterCondition, error) {
+	cond := FilterCondition{Field: field, Operator: op}
+
+	switch op {
+	case FilterIn, FilterNin:
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				cond.Values = append(cond.Values, v)
+			}
+		}
+		if len(cond.Values) == 0 {
+			return cond, fmt.Errorf("%w: filter[%s][%s] needs at least one value", ErrInvalidQuery, field, op)
+		}
+	case FilterBetween:
+		lo, hi, ok := strings.Cut(raw, ",")
+		lo, hi = strings.TrimSpace(lo), strings.TrimSpace(hi)
+		if !ok || lo == "" || hi == "" || strings.Contains(hi, ",") {
+			return cond, fmt.Errorf("%w: filter[%s][between] needs two values, e.g. 18,65", ErrInvalidQuery, field)
+		}
+		cond.Values = []string{lo, hi}
+	case FilterNull:
+		switch strings.ToLower(strings.TrimSpace(raw)) {
+		case "true", "1":
+			cond.Values = []string{"true"}
+		case "false", "0":
+			cond.Values = []string{"false"}
+		default:
+			return cond, fmt.Errorf("%w: filter[%s][null] must be true or false", ErrInvalidQuery, field)
+		}
+	default:
+		cond.Values = []string{raw}
+	}
+	return cond, nil
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []FilterCondition
+	}{
+		{"default eq", "filter[name]=Andi", []FilterCondition{{Field: "name", Operator: FilterEq, Values: []string{"Andi"}}}},
+		{"eq", "filter[name][eq]=Andi", []FilterCondition{{Field: "name", Operator: FilterEq, Values: []string{"Andi"}}}},
+		{"ne", "filter[age][ne]=20", []FilterCondition{{Field: "age", Operator: FilterNe, Values: []string{"20"}}}},
+		{"gt", "filter[age][gt]=20", []FilterCondition{{Field: "age", Operator: FilterGt, Values: []string{"20"}}}},
+		{"gte", "filter[age][gte]=20", []FilterCondition{{Field: "age", Operator: FilterGte, Values: []string{"20"}}}},
+		{"lt", "filter[age][lt]=20", []FilterCondition{{Field: "age", Operator: FilterLt, Values: []string{"20"}}}},
+		{"lte", "filter[age][lte]=20", []FilterCondition{{Field: "age", Operator: FilterLte, Values: []string{"20"}}}},
+		{"like", "filter[name][like]=an", []FilterCondition{{Field: "name", Operator: FilterLike, Values: []string{"an"}}}},
+		{"in", "filter[age][in]=20, 30,,40", []FilterCondition{{Field: "age", Operator: FilterIn, Values: []string{"20", "30", "40"}}}},
+		{"in one value", "filter[age][in]=20", []FilterCondition{{Field: "age", Operator: FilterIn, Values: []string{"20"}}}},
+		{"nin", "filter[age][nin]=20,30", []FilterCondition{{Field: "age", Operator: FilterNin, Values: []string{"20", "30"}}}},
+		{"between", "filter[age][between]=18, 65", []FilterCondition{{Field: "age", Operator: FilterBetween, Values: []string{"18", "65"}}}},
+		{"null true", "filter[score][null]=TRUE", []FilterCondition{{Field: "score", Operator: FilterNull, Values: []string{"true"}}}},
+		{"null 1", "filter[score][null]=1", []FilterCondition{{Field: "score", Operator: FilterNull, Values: []string{"true"}}}},
+		{"null false", "filter[score][null]=false", []FilterCondition{{Field: "score", Operator: FilterNull, Values: []string{"false"}}}},
+		{"null 0", "filter[score][null]=0", []FilterCondition{{Field: "score", Operator: FilterNull, Values: []string{"false"}}}},
+		{"operator case", "filter[age][GTE]=20", []FilterCondition{{Field: "age", Operator: FilterGte, Values: []string{"20"}}}},
+		{"repeated", "filter[age][ne]=20&filter[age][ne]=30", []FilterCondition{
+			{Field: "age", Operator: FilterNe, Values: []string{"20"}},
+			{Field: "age", Operator: FilterNe, Values: []string{"30"}},
+		}},
+		{"sorted by key", "filter[name]=Andi&filter[age]=20", []FilterCondition{
+			{Field: "age", Operator: FilterEq, Values: []string{"20"}},
+			{Field: "name", Operator: FilterEq, Values: []string{"Andi"}},
+		}},
+		{"other parameters", "page=2&sort=name", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseFilters(values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFiltersRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		raw  string
+	}{
+		{"unknown operator", "filter[age][approx]", "20"},
+		{"empty field", "filter[]", "20"},
+		{"too many parts", "filter[age][gt][x]", "20"},
+		{"unclosed bracket", "filter[age", "20"},
+		{"text after brackets", "filter[age]x", "20"},
+		{"in without values", "filter[age][in]", " , ,"},
+		{"nin without values", "filter[age][nin]", ""},
+		{"between one value", "filter[age][between]", "18"},
+		{"between three values", "filter[age][between]", "18,30,65"},
+		{"between missing min", "filter[age][between]", ",65"},
+		{"between missing max", "filter[age][between]", "18,"},
+		{"null empty", "filter[score][null]", ""},
+		{"null yes", "filter[score][null]", "yes"},
+		{"null value", "filter[score][null]", "20"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilters(url.Values{tt.key: {tt.raw}})
+			if !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("%s=%q: got %+v, %v; want ErrInvalidQuery", tt.key, tt.raw, got, err)
+			}
+		})
+	}
+}
@@ -7,13 +7,13 @@ import (
 )
 
 type RecordCreatedModel struct {
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	CreatedBy uint      `gorm:"column:created_by" json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at" sortable:"true" filterable:"true" selectable:"true"`
+	CreatedBy uint      `gorm:"column:created_by" json:"created_by" filterable:"true" selectable:"true"`
 }
 
 type RecordUpdatedModel struct {
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
-	UpdatedBy uint      `gorm:"column:updated_by" json:"updated_by"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at" sortable:"true" filterable:"true" selectable:"true"`
+	UpdatedBy uint      `gorm:"column:updated_by" json:"updated_by" filterable:"true" selectable:"true"`
 }
 
 type RecordModel struct {
@@ -27,10 +27,10 @@ type SoftDeleteModel struct {
 }
 
 type UUIDModel struct {
-	UUID string `gorm:"size:36;uniqueIndex;not null;column:uuid" json:"uuid"`
+	UUID string `gorm:"size:36;uniqueIndex;not null;column:uuid" json:"uuid" filterable:"true" selectable:"true"`
 }
 
 type BaseModel struct {
-	ID uint `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ID uint `gorm:"primaryKey;autoIncrement;column:id" json:"id" sortable:"true" filterable:"true" selectable:"true"`
 	UUIDModel
 }
@@ -3,13 +3,22 @@ package types
 
 // QueryParams represents query parameters for filtering, pagination, and sorting.
 type QueryParams struct {
-	Search   string                 `form:"search"`
-	Filter   map[string]interface{} `form:"filter"`
-	Sort     string                 `form:"sort"`
-	Page     int                    `form:"page"`
-	PageSize int                    `form:"page_size"`
-	Fields   string                 `form:"fields"`
-	Include  string                 `form:"include"`
+	Search string `form:"search"`
+	// Filter is parsed from filter[field][op]=value parameters by
+	// BindFilters, since form binding cannot express them.
+	Filter   []FilterCondition `form:"-"`
+	Sort     string            `form:"sort"`
+	Page     int               `form:"page"`
+	PageSize int               `form:"page_size"`
+	Fields   string            `form:"fields"`
+	Include  string            `form:"include"`
+	// Cursor and Limit select keyset pagination: Limit rows after (or
+	// before) the row the opaque cursor points at, without OFFSET or COUNT.
+	// Page is ignored when either is set.
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+	// SkipCount leaves out the total count in page mode.
+	SkipCount bool `form:"skip_count"`
 }
 
 // Response represents a standard API response structure.
@@ -20,12 +29,16 @@ type Response struct {
 	Meta    *Meta       `json:"meta,omitempty"`
 }
 
-// Meta represents pagination metadata.
+// Meta represents pagination metadata. Total and Pages are nil when the
+// count was skipped; in cursor mode Page is zero and the next and previous
+// pages are addressed by NextCursor and PrevCursor instead.
 type Meta struct {
-	Page     int `json:"page"`
-	PageSize int `json:"page_size"`
-	Total    int `json:"total"`
-	Pages    int `json:"pages"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      *int   `json:"total,omitempty"`
+	Pages      *int   `json:"pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 // NewSuccessResponse creates a new success response.
@@ -45,8 +58,20 @@ func NewErrorResponse(message string) Response {
 	}
 }
 
+// UsesCursor reports whether the query asks for cursor pagination.
+func (q *QueryParams) UsesCursor() bool {
+	return q.Cursor != "" || q.Limit > 0
+}
+
 // SetDefaultPagination sets default values for pagination parameters.
 func (q *QueryParams) SetDefaultPagination() {
+	if q.UsesCursor() {
+		if q.Limit <= 0 {
+			q.Limit = 10
+		} else if q.Limit > 100 {
+			q.Limit = 100
+		}
+	}
 	if q.Page <= 0 {
 		q.Page = 1
 	}
@@ -62,9 +87,13 @@ func (m *Meta) CalculatePages() {
 	if m.PageSize <= 0 {
 		m.PageSize = 10
 	}
-	if m.Total > 0 {
-		m.Pages = (m.Total + m.PageSize - 1) / m.PageSize
-	} else {
-		m.Pages = 0
+	if m.Total == nil {
+		m.Pages = nil
+		return
+	}
+	pages := 0
+	if *m.Total > 0 {
+		pages = (*m.Total + m.PageSize - 1) / m.PageSize
 	}
+	m.Pages = &pages
 }
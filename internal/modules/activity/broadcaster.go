@@ -0,0 +1,129 @@
+package activity
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// StreamFilter selects which activity logs a subscriber receives. Zero
+// values match everything.
+type StreamFilter struct {
+	StatusMin  int    `form:"status_min"`
+	StatusMax  int    `form:"status_max"`
+	PathPrefix string `form:"path_prefix"`
+	UserID     *uint  `form:"user_id"`
+}
+
+// Match reports whether entry passes the filter. PathPrefix is matched
+// against the requested path, so it also matches unmatched routes.
+func (f StreamFilter) Match(entry *ActivityLog) bool {
+	if f.StatusMin != 0 && entry.Status < f.StatusMin {
+		return false
+	}
+	if f.StatusMax != 0 && entry.Status > f.StatusMax {
+		return false
+	}
+	if f.PathPrefix != "" && !strings.HasPrefix(entry.RawPath, f.PathPrefix) {
+		return false
+	}
+	if f.UserID != nil && (entry.UserID == nil || *entry.UserID != *f.UserID) {
+		return false
+	}
+	return true
+}
+
+// Subscription receives the activity logs matching its filter on C. C is
+// closed when the subscription ends.
+type Subscription struct {
+	C      <-chan ActivityLog
+	ch     chan ActivityLog
+	filter StreamFilter
+
+	dropped atomic.Uint64
+}
+
+// Dropped returns and resets how many entries were dropped because the
+// subscriber did not keep up.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Swap(0)
+}
+
+// Broadcaster fans out recorded activity logs to live subscribers. Publish
+// never blocks: entries for a subscriber whose buffer is full are dropped.
+type Broadcaster struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// NewBroadcaster creates an empty Broadcaster.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe registers a subscriber with room for buffer pending entries. It
+// returns nil once the broadcaster is closed.
+func (b *Broadcaster) Subscribe(filter StreamFilter, buffer int) *Subscription {
+	ch := make(chan ActivityLog, buffer)
+	sub := &Subscription{C: ch, ch: ch, filter: filter}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its channel.
+func (b *Broadcaster) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Publish sends entry to every subscriber whose filter matches. The captured
+// request and response bodies are left out: streams are not authenticated,
+// and bodies may hold credentials or personal data.
+func (b *Broadcaster) Publish(entry ActivityLog) {
+	entry.RequestBody, entry.ResponseBody = "", ""
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if !sub.filter.Match(&entry) {
+			continue
+		}
+		select {
+		case sub.ch <- entry:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribers returns the number of live subscribers.
+func (b *Broadcaster) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// Close ends every subscription and refuses new ones.
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
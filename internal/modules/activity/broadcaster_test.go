@@ -0,0 +1,19 @@
+package activity
+
+import "testing"
+
+func TestPublishOmitsBodies(t *testing.T) {
+	b := NewBroadcaster()
+	sub := b.Subscribe(StreamFilter{}, 1)
+	defer b.Unsubscribe(sub)
+
+	b.Publish(ActivityLog{Path: "/login", RequestBody: `{"password":"secret"}`, ResponseBody: `{"token":"t"}`})
+
+	entry := <-sub.C
+	if entry.Path != "/login" {
+		t.Errorf("Path = %q, want /login", entry.Path)
+	}
+	if entry.RequestBody != "" || entry.ResponseBody != "" {
+		t.Errorf("bodies were published: request %q, response %q", entry.RequestBody, entry.ResponseBody)
+	}
+}
@@ -1,7 +1,10 @@
 package activity
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"study1/internal/core/types"
 
@@ -9,17 +12,28 @@ import (
 )
 
 type ActivityHandler struct {
-	service *ActivityService
+	service     *ActivityService
+	broadcaster *Broadcaster
 }
 
-func NewActivityHandler(service *ActivityService) *ActivityHandler {
-	return &ActivityHandler{service: service}
+func NewActivityHandler(service *ActivityService, broadcaster *Broadcaster) *ActivityHandler {
+	return &ActivityHandler{service: service, broadcaster: broadcaster}
 }
 
+const (
+	// streamBuffer is how many entries a slow stream client may fall behind
+	// before entries are dropped for it.
+	streamBuffer = 256
+	// streamHeartbeat keeps idle streams alive through proxies.
+	streamHeartbeat = 15 * time.Second
+)
+
 func (h *ActivityHandler) RegisterRoutes(router *gin.RouterGroup) {
 	g := router.Group("/activity-logs")
 	{
 		g.GET("", h.GetManys)
+		g.GET("/stats", h.GetStats)
+		g.GET("/stream", h.Stream)
 		g.GET("/:uuid", h.GetOnes)
 	}
 }
@@ -32,7 +46,12 @@ func (h *ActivityHandler) RegisterRoutes(router *gin.RouterGroup) {
 // @Produce json
 // @Param page query int false "Page number"
 // @Param page_size query int false "Page size"
+// @Param skip_count query bool false "Leave out total and pages"
+// @Param limit query int false "Page size for cursor pagination (default 10, max 100)"
+// @Param cursor query string false "next_cursor or prev_cursor from a previous response"
+// @Param filter query string false "Filters as filter[field][op]=value; op is eq (default), ne, gt, gte, lt, lte, like, in, nin, between or null, e.g. filter[age][gte]=18"
 // @Param search query string false "Search term"
+// @Param fields query string false "Comma-separated JSON fields to return, e.g. name,email"
 // @Success 200 {object} types.Response
 // @Failure 400 {object} types.Response
 // @Failure 500 {object} types.Response
@@ -43,15 +62,126 @@ func (h *ActivityHandler) GetManys(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, types.NewErrorResponse(err.Error()))
 		return
 	}
+	if err := params.BindFilters(c.Request.URL.Query()); err != nil {
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse(err.Error()))
+		return
+	}
 	params.SetDefaultPagination()
 
 	logs, meta, err := h.service.GetManys(params)
+	if errors.Is(err, types.ErrInvalidQuery) {
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse(err.Error()))
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.NewErrorResponse(err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, types.NewSuccessResponse(logs, meta))
+	// Only the requested fields are sent, not zero values for the rest
+	var data interface{} = logs
+	if keys := params.FieldList(); keys != nil {
+		if data, err = types.SelectFields(logs, keys); err != nil {
+			c.JSON(http.StatusInternalServerError, types.NewErrorResponse(err.Error()))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse(data, meta))
+}
+
+// @Summary Activity statistics
+// @Description Request counts, error rates (5xx) and p50/p95/p99 latency over a window, optionally grouped
+// @Tags activity
+// @Accept json
+// @Produce json
+// @Param from query string false "Window start (RFC3339), defaults to to minus window"
+// @Param to query string false "Window end (RFC3339), defaults to now"
+// @Param window query string false "Window length when from is not set, e.g. 1h (default 24h)"
+// @Param group_by query string false "Comma-separated: path, method, status_class, user, time"
+// @Param bucket query string false "Time bucket when grouping by time: minute, hour (default) or day, in UTC"
+// @Param sort query string false "count (default), error_rate, p50, p95 or p99"
+// @Param limit query int false "Maximum number of groups (default 50, max 1000)"
+// @Success 200 {object} types.Response
+// @Failure 400 {object} types.Response
+// @Failure 500 {object} types.Response
+// @Router /activity-logs/stats [get]
+func (h *ActivityHandler) GetStats(c *gin.Context) {
+	var query StatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse(err.Error()))
+		return
+	}
+
+	stats, err := h.service.GetStats(query)
+	if errors.Is(err, ErrInvalidStatsQuery) {
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse(err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.NewErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse(stats, nil))
+}
+
+// @Summary Stream activity logs
+// @Description Push newly recorded activity logs as Server-Sent Events ("activity"), without request and response bodies. A "dropped" event reports entries skipped because the client fell behind; "ping" is sent while idle.
+// @Tags activity
+// @Produce text/event-stream
+// @Param status_min query int false "Minimum status code"
+// @Param status_max query int false "Maximum status code"
+// @Param path_prefix query string false "Only requests whose path starts with this prefix"
+// @Param user_id query int false "Only requests by this user"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} types.Response
+// @Failure 503 {object} types.Response
+// @Router /activity-logs/stream [get]
+func (h *ActivityHandler) Stream(c *gin.Context) {
+	var filter StreamFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse(err.Error()))
+		return
+	}
+	if filter.StatusMin != 0 && filter.StatusMax != 0 && filter.StatusMin > filter.StatusMax {
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse("status_min must not be greater than status_max"))
+		return
+	}
+
+	sub := h.broadcaster.Subscribe(filter, streamBuffer)
+	if sub == nil {
+		c.JSON(http.StatusServiceUnavailable, types.NewErrorResponse("Server is shutting down"))
+		return
+	}
+	defer h.broadcaster.Unsubscribe(sub)
+
+	// A stream outlives the server's write timeout.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case entry, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+			if n := sub.Dropped(); n > 0 {
+				c.SSEvent("dropped", gin.H{"count": n})
+			}
+			c.SSEvent("activity", entry)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
 }
 
 // @Summary Get an activity log
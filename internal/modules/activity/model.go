@@ -1,23 +1,43 @@
 package activity
 
 import (
+	"time"
+
+	"study1/internal/core/database"
 	"study1/internal/core/types"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+func init() {
+	database.RegisterModels(ModuleName, &ActivityLog{})
+}
+
 // ActivityLog represents an HTTP activity / access log stored in the database.
 type ActivityLog struct {
 	types.BaseModel
-	Method    string `gorm:"size:16" json:"method"`
-	Path      string `gorm:"size:1024" json:"path"`
-	Status    int    `json:"status"`
-	LatencyMs int64  `json:"latency_ms"`
-	IP        string `gorm:"size:64" json:"ip"`
-	UserAgent string `gorm:"size:512" json:"user_agent"`
-	UserID    *uint  `json:"user_id"`
-	types.RecordCreatedModel
+	RequestID string `gorm:"size:64;index" json:"request_id" filterable:"true" selectable:"true"`
+	Method    string `gorm:"size:16" json:"method" sortable:"true" filterable:"true" selectable:"true"`
+	// Path is the matched route pattern; RawPath is the path as requested
+	// and is also set for unmatched routes.
+	Path         string `gorm:"size:1024" json:"path" searchable:"true" sortable:"true" filterable:"true" selectable:"true"`
+	RawPath      string `gorm:"size:2048" json:"raw_path" searchable:"true" filterable:"true" selectable:"true"`
+	Query        string `gorm:"size:2048" json:"query" selectable:"true"`
+	Status       int    `json:"status" sortable:"true" filterable:"true" selectable:"true"`
+	ResponseSize int    `json:"response_size" sortable:"true" filterable:"true" selectable:"true"`
+	LatencyMs    int64  `json:"latency_ms" sortable:"true" filterable:"true" selectable:"true"`
+	IP           string `gorm:"size:64" json:"ip" filterable:"true" selectable:"true"`
+	UserAgent    string `gorm:"size:512" json:"user_agent" selectable:"true"`
+	UserID       *uint  `json:"user_id" sortable:"true" filterable:"true" selectable:"true"`
+	Errors       string `gorm:"type:text" json:"errors,omitempty" selectable:"true"`
+	// Bodies are only stored for routes using middleware.CaptureBody.
+	RequestBody  string `gorm:"type:text" json:"request_body,omitempty" selectable:"true"`
+	ResponseBody string `gorm:"type:text" json:"response_body,omitempty" selectable:"true"`
+	// Same columns as types.RecordCreatedModel, with created_at indexed for
+	// retention pruning and archival.
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at" sortable:"true" filterable:"true" selectable:"true"`
+	CreatedBy uint      `gorm:"column:created_by" json:"created_by" filterable:"true" selectable:"true"`
 }
 
 // TableName returns the table name used by GORM.
@@ -25,6 +45,11 @@ func (ActivityLog) TableName() string {
 	return "activity_logs"
 }
 
+// DefaultSort lists newest first.
+func (ActivityLog) DefaultSort() string {
+	return "-created_at"
+}
+
 // BeforeCreate hook populates UUID if not set.
 func (u *ActivityLog) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.UUID == "" {
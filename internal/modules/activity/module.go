@@ -1,27 +1,123 @@
 package activity
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"study1/internal/core/config"
 	"study1/internal/core/database"
+	httpmw "study1/internal/core/http/middleware"
+	"study1/internal/core/module"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+// ModuleName identifies the activity module in the model and module registries.
+const ModuleName = "activity"
+
+func init() {
+	module.Register(func(cfg *config.Config, db *database.DB) module.Module {
+		return NewActivityModule(db, cfg.Activity)
+	})
+}
+
 type ActivityModule struct {
-	Repository ActivityRepository
-	Service    *ActivityService
-	Handler    *ActivityHandler
+	module.Base
+
+	Repository  ActivityRepository
+	Service     *ActivityService
+	Handler     *ActivityHandler
+	Writer      *Writer
+	Pruner      *Pruner
+	Broadcaster *Broadcaster
+
+	redactFields []string
 }
 
-func NewActivityModule(db *database.DB) *ActivityModule {
+func NewActivityModule(db *database.DB, cfg config.ActivityConfig) *ActivityModule {
 	repo := NewActivityRepository(db)
 	service := NewActivityService(repo)
-	handler := NewActivityHandler(service)
+	broadcaster := NewBroadcaster()
+	handler := NewActivityHandler(service, broadcaster)
 
 	return &ActivityModule{
+		Base:       module.Base{ModuleName: ModuleName, DB: db},
 		Repository: repo,
 		Service:    service,
 		Handler:    handler,
+		Writer: NewWriter(&repo, WriterConfig{
+			BufferSize:    cfg.BufferSize,
+			BatchSize:     cfg.BatchSize,
+			FlushInterval: cfg.FlushInterval,
+		}),
+		Pruner: NewPruner(repo, PrunerConfig{
+			Retention: time.Duration(cfg.RetentionDays) * 24 * time.Hour,
+			Interval:  cfg.PruneInterval,
+			BatchSize: cfg.PruneBatchSize,
+		}),
+		Broadcaster:  broadcaster,
+		redactFields: cfg.RedactFields,
+	}
+}
+
+// Middleware records every request through the buffered writer.
+func (m *ActivityModule) Middleware() []gin.HandlerFunc {
+	opts := httpmw.ActivityOptions{RedactFields: m.redactFields}
+	return []gin.HandlerFunc{httpmw.ActivityLogger(opts, m.record)}
+}
+
+// record queues the entry for writing and publishes it to live streams. The
+// UUID and timestamp are set here so streamed entries match the stored ones.
+func (m *ActivityModule) record(info httpmw.RequestInfo) {
+	entry := ActivityLog{
+		RequestID:    info.RequestID,
+		Method:       info.Method,
+		Path:         info.Path,
+		RawPath:      info.RawPath,
+		Query:        info.Query,
+		Status:       info.Status,
+		ResponseSize: info.ResponseSize,
+		LatencyMs:    info.LatencyMs,
+		IP:           info.IP,
+		UserAgent:    info.UserAgent,
+		UserID:       info.UserID,
+		Errors:       info.Errors,
+		RequestBody:  info.RequestBody,
+		ResponseBody: info.ResponseBody,
+	}
+	entry.UUID = uuid.New().String()
+	entry.CreatedAt = time.Now()
+	if entry.UserID != nil {
+		entry.CreatedBy = *entry.UserID
+	}
+
+	m.Writer.Write(entry)
+	m.Broadcaster.Publish(entry)
+}
+
+// OnShutdown ends live activity streams so the server can drain.
+func (m *ActivityModule) OnShutdown() {
+	m.Broadcaster.Close()
+}
+
+// Start starts the activity log writer and the retention pruner.
+func (m *ActivityModule) Start(ctx context.Context) error {
+	m.Writer.Start()
+	m.Pruner.Start()
+	return nil
+}
+
+// Stop stops pruning and drains buffered activity logs to the database.
+func (m *ActivityModule) Stop(ctx context.Context) error {
+	if err := m.Pruner.Stop(ctx); err != nil {
+		return fmt.Errorf("stop activity log pruner: %w", err)
+	}
+	if err := m.Writer.Close(ctx); err != nil {
+		return fmt.Errorf("flush activity logs: %w", err)
 	}
+	return nil
 }
 
 func (m *ActivityModule) RegisterRoutes(router *gin.RouterGroup) {
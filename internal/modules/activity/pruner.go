@@ -0,0 +1,105 @@
+package activity
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// PrunerConfig controls the retention of activity logs.
+type PrunerConfig struct {
+	// Retention is how long logs are kept. Zero disables pruning.
+	Retention time.Duration
+	// Interval is how often old logs are pruned.
+	Interval time.Duration
+	// BatchSize is how many rows are deleted per statement, so pruning a
+	// large backlog does not hold long locks.
+	BatchSize int
+}
+
+// Pruner periodically deletes activity logs older than the retention period.
+type Pruner struct {
+	repo ActivityRepository
+	cfg  PrunerConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPruner creates a Pruner. Call Start to begin pruning.
+func NewPruner(repo ActivityRepository, cfg PrunerConfig) *Pruner {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	return &Pruner{repo: repo, cfg: cfg}
+}
+
+// Start prunes once and then every interval until Stop is called. It does
+// nothing when retention is disabled.
+func (p *Pruner) Start() {
+	if p.cfg.Retention <= 0 || p.done != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go p.run(ctx)
+}
+
+// Stop interrupts pruning and waits for it to finish or ctx to be done.
+func (p *Pruner) Stop(ctx context.Context) error {
+	if p.done == nil {
+		return nil
+	}
+	p.cancel()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pruner) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.Prune(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune deletes logs older than the retention period in batches and returns
+// how many were deleted.
+func (p *Pruner) Prune(ctx context.Context) int64 {
+	cutoff := time.Now().Add(-p.cfg.Retention)
+
+	var total int64
+	for ctx.Err() == nil {
+		n, err := p.repo.DeleteCreatedBefore(cutoff, 0, p.cfg.BatchSize)
+		total += n
+		if err != nil {
+			log.Printf("⚠️  Failed to prune activity logs: %v", err)
+			break
+		}
+		if n < int64(p.cfg.BatchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("✅ Pruned %d activity log(s) older than %s", total, cutoff.Format(time.RFC3339))
+	}
+	return total
+}
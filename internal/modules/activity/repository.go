@@ -1,6 +1,10 @@
 package activity
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"study1/internal/core/database"
 	"study1/internal/core/repository"
 	"study1/internal/core/types"
@@ -11,6 +15,8 @@ type ActivityRepository struct {
 	db          *database.DB
 }
 
+// NewActivityRepository creates the activity log repository. db may be a
+// transaction from database.WithTransaction.
 func NewActivityRepository(db *database.DB) ActivityRepository {
 	return ActivityRepository{
 		genericRepo: repository.NewGenericRepository[ActivityLog](db),
@@ -51,3 +57,167 @@ func (r *ActivityRepository) DeleteManys(uuids []string) error {
 func (r *ActivityRepository) DeleteOnes(uuid string) error {
 	return r.genericRepo.DeleteOnes(uuid)
 }
+
+// FindCreatedBefore returns up to limit logs created before cutoff with an ID
+// greater than afterID, ordered by ID, for walking old rows in chunks.
+func (r *ActivityRepository) FindCreatedBefore(cutoff time.Time, afterID uint, limit int) ([]ActivityLog, error) {
+	var logs []ActivityLog
+	err := r.db.Where("created_at < ? AND id > ?", cutoff, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&logs).Error
+	return logs, err
+}
+
+// DeleteCreatedBefore deletes up to limit logs created before cutoff and
+// returns how many were deleted. When maxID is not zero only logs with an ID
+// up to maxID are deleted. IDs are selected first because MySQL does not
+// support LIMIT in a DELETE subquery.
+func (r *ActivityRepository) DeleteCreatedBefore(cutoff time.Time, maxID uint, limit int) (int64, error) {
+	query := r.db.Model(&ActivityLog{}).Where("created_at < ?", cutoff)
+	if maxID != 0 {
+		query = query.Where("id <= ?", maxID)
+	}
+
+	var ids []uint
+	if err := query.Order("id").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.Where("id IN ?", ids).Delete(&ActivityLog{})
+	return result.RowsAffected, result.Error
+}
+
+// statsKeySQL returns the SQL expression of a stats dimension and the
+// statsKeys column it is read into. Time buckets are counted from the Unix
+// epoch in UTC, for a window ending at to.
+func (r *ActivityRepository) statsKeySQL(dim string, bucket time.Duration, to time.Time) (expr, column string) {
+	switch dim {
+	case GroupByPath:
+		// Unmatched routes have no pattern; grouping them by raw path would
+		// let scanners create a group per URL.
+		return "COALESCE(NULLIF(path, ''), '(unmatched)')", "path"
+	case GroupByMethod:
+		return "method", "method"
+	case GroupByStatusClass:
+		return "status - status % 100", "status_class"
+	case GroupByUser:
+		return "user_id", "user_id"
+	case GroupByTime:
+		seconds := int64(bucket / time.Second)
+		switch r.db.Dialector.Name() {
+		case database.DriverPostgres:
+			return fmt.Sprintf("CAST(FLOOR(EXTRACT(EPOCH FROM created_at) / %d) AS BIGINT)", seconds), "bucket"
+		case database.DriverSQLite:
+			return fmt.Sprintf("CAST(strftime('%%s', created_at) AS INTEGER) / %d", seconds), "bucket"
+		default:
+			// DATETIME holds wall-clock time in the connection's location,
+			// which UNIX_TIMESTAMP would read in the session time zone
+			// instead. The offset is the location's at the end of the
+			// window, exact for zones without daylight saving time such as
+			// the default Asia/Jakarta.
+			_, offset := to.In(database.Location(r.db.DB)).Zone()
+			return fmt.Sprintf("(TIMESTAMPDIFF(SECOND, '1970-01-01 00:00:00', created_at) - %d) DIV %d", offset, seconds), "bucket"
+		}
+	}
+	return "", ""
+}
+
+// statsAggregates counts the logs created in [from, to), their errors and
+// client errors and sums up their latency, one row per combination of the
+// groupBy dimensions or a single row without any. Postgres computes latency
+// percentiles as well.
+func (r *ActivityRepository) statsAggregates(from, to time.Time, groupBy []string, bucket time.Duration) ([]statsAggregate, error) {
+	// Groups are keyed by expression rather than alias; Postgres would read
+	// the path alias as the path column.
+	var keys, columns []string
+	for _, dim := range groupBy {
+		expr, column := r.statsKeySQL(dim, bucket, to)
+		columns = append(columns, expr+" AS "+column)
+		keys = append(keys, expr)
+	}
+	columns = append(columns,
+		"COUNT(*) AS requests",
+		"COALESCE(SUM(CASE WHEN status >= 500 THEN 1 ELSE 0 END), 0) AS errors",
+		"COALESCE(SUM(CASE WHEN status >= 400 AND status < 500 THEN 1 ELSE 0 END), 0) AS client_errors",
+		"COALESCE(SUM(latency_ms), 0) AS latency_sum",
+		"COALESCE(MAX(latency_ms), 0) AS latency_max",
+	)
+	if r.percentilesInSQL() {
+		columns = append(columns,
+			"percentile_disc(0.5) WITHIN GROUP (ORDER BY latency_ms) AS p50",
+			"percentile_disc(0.95) WITHIN GROUP (ORDER BY latency_ms) AS p95",
+			"percentile_disc(0.99) WITHIN GROUP (ORDER BY latency_ms) AS p99",
+		)
+	}
+
+	query := r.db.Model(&ActivityLog{}).
+		Select(strings.Join(columns, ", ")).
+		Where("created_at >= ? AND created_at < ?", from, to)
+	if len(keys) > 0 {
+		query = query.Group(strings.Join(keys, ", "))
+	}
+
+	var rows []statsAggregate
+	err := query.Scan(&rows).Error
+	return rows, err
+}
+
+// percentilesInSQL reports whether statsAggregates computes percentiles.
+// MySQL and SQLite have no percentile function, so theirs are computed
+// from scanLatencySamples.
+func (r *ActivityRepository) percentilesInSQL() bool {
+	return r.db.Dialector.Name() == database.DriverPostgres
+}
+
+// scanLatencySamples streams the latency and groupBy values of at most size
+// logs per group created in [from, to) to fn. Larger groups are sampled by
+// taking every n-th log in ID order, so the sample spans the window and
+// every group keeps at least one log.
+func (r *ActivityRepository) scanLatencySamples(from, to time.Time, groupBy []string, bucket time.Duration, size int, fn func(sample latencySample)) error {
+	var keys, selected []string
+	ranked := []string{"latency_ms"}
+	for _, dim := range groupBy {
+		expr, column := r.statsKeySQL(dim, bucket, to)
+		keys = append(keys, expr)
+		ranked = append(ranked, expr+" AS "+column)
+		selected = append(selected, column)
+	}
+	partition := ""
+	if len(keys) > 0 {
+		partition = "PARTITION BY " + strings.Join(keys, ", ") + " "
+	}
+	ranked = append(ranked,
+		"ROW_NUMBER() OVER ("+partition+"ORDER BY id) AS sample_rank",
+		"COUNT(*) OVER ("+partition+") AS group_size",
+	)
+	intDiv := "/"
+	if r.db.Dialector.Name() == database.DriverMySQL {
+		intDiv = "DIV"
+	}
+
+	logs := r.db.Model(&ActivityLog{}).
+		Select(strings.Join(ranked, ", ")).
+		Where("created_at >= ? AND created_at < ?", from, to)
+	// Every n-th row, n being group_size / size rounded up.
+	rows, err := r.db.Table("(?) AS ranked", logs).
+		Select(strings.Join(append([]string{"latency_ms"}, selected...), ", ")).
+		Where(fmt.Sprintf("sample_rank %% ((group_size + ?) %s ?) = 0", intDiv), size-1, size).
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sample latencySample
+		if err := r.db.ScanRows(rows, &sample); err != nil {
+			return err
+		}
+		fn(sample)
+	}
+	return rows.Err()
+}
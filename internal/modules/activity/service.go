@@ -1,6 +1,8 @@
 package activity
 
 import (
+	"time"
+
 	"study1/internal/core/types"
 )
 
@@ -26,3 +28,41 @@ func (s *ActivityService) GetManys(params types.QueryParams) ([]ActivityLog, *ty
 func (s *ActivityService) GetOnes(uuid string) (*ActivityLog, error) {
 	return s.repo.FindOnes(uuid)
 }
+
+// GetStats returns request counts, error rates and latency percentiles for
+// the query's window, grouped as requested.
+func (s *ActivityService) GetStats(query StatsQuery) (*StatsResult, error) {
+	if err := query.Normalize(time.Now()); err != nil {
+		return nil, err
+	}
+
+	agg := newStatsAggregator(&query)
+	totals, err := s.repo.statsAggregates(query.From, query.To, nil, query.bucket)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range totals {
+		agg.addTotal(row)
+	}
+	if len(query.groupBy) > 0 {
+		groups, err := s.repo.statsAggregates(query.From, query.To, query.groupBy, query.bucket)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range groups {
+			agg.add(row)
+		}
+	}
+
+	if !s.repo.percentilesInSQL() && agg.total.Count > 0 {
+		if err := s.repo.scanLatencySamples(query.From, query.To, nil, query.bucket, maxLatencySamples, agg.addTotalSample); err != nil {
+			return nil, err
+		}
+		if len(query.groupBy) > 0 {
+			if err := s.repo.scanLatencySamples(query.From, query.To, query.groupBy, query.bucket, agg.groupSampleSize(), agg.addSample); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return agg.result(), nil
+}
@@ -0,0 +1,382 @@
+package activity
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Stats dimensions accepted in StatsQuery.GroupBy.
+const (
+	GroupByPath        = "path"
+	GroupByMethod      = "method"
+	GroupByStatusClass = "status_class"
+	GroupByUser        = "user"
+	GroupByTime        = "time"
+)
+
+// Time buckets accepted in StatsQuery.Bucket.
+var statsBuckets = map[string]time.Duration{
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+}
+
+// Sort orders accepted in StatsQuery.Sort, all descending.
+var statsSorts = map[string]func(a, b *StatsGroup) bool{
+	"count":      func(a, b *StatsGroup) bool { return a.Count > b.Count },
+	"error_rate": func(a, b *StatsGroup) bool { return a.ErrorRate > b.ErrorRate },
+	"p50":        func(a, b *StatsGroup) bool { return a.Latency.P50 > b.Latency.P50 },
+	"p95":        func(a, b *StatsGroup) bool { return a.Latency.P95 > b.Latency.P95 },
+	"p99":        func(a, b *StatsGroup) bool { return a.Latency.P99 > b.Latency.P99 },
+}
+
+const (
+	defaultStatsWindow = 24 * time.Hour
+	defaultStatsLimit  = 50
+	maxStatsLimit      = 1000
+)
+
+// ErrInvalidStatsQuery is returned for stats queries that cannot be run.
+var ErrInvalidStatsQuery = errors.New("invalid stats query")
+
+// StatsQuery selects the window and grouping of activity statistics.
+type StatsQuery struct {
+	// From and To bound the window (RFC3339). To defaults to now; From
+	// defaults to To minus Window.
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Window is a duration such as "1h", used when From is not set (default 24h).
+	Window string `form:"window"`
+	// GroupBy is a comma-separated list of path, method, status_class, user
+	// and time. Empty returns a single group for the whole window.
+	GroupBy string `form:"group_by"`
+	// Bucket is the time bucket for GroupByTime: minute, hour or day, in UTC.
+	Bucket string `form:"bucket"`
+	// Sort orders groups by count, error_rate, p50, p95 or p99 (descending).
+	// Groups are ordered by time first when grouping by time.
+	Sort  string `form:"sort"`
+	Limit int    `form:"limit"`
+
+	groupBy []string
+	bucket  time.Duration
+}
+
+// Normalize applies defaults and validates the query.
+func (q *StatsQuery) Normalize(now time.Time) error {
+	if q.To.IsZero() {
+		q.To = now
+	}
+	if q.From.IsZero() {
+		window := defaultStatsWindow
+		if q.Window != "" {
+			d, err := time.ParseDuration(q.Window)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("%w: window must be a positive duration such as 1h", ErrInvalidStatsQuery)
+			}
+			window = d
+		}
+		q.From = q.To.Add(-window)
+	}
+	if !q.From.Before(q.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidStatsQuery)
+	}
+
+	q.groupBy = nil
+	for _, dim := range strings.Split(q.GroupBy, ",") {
+		dim = strings.TrimSpace(dim)
+		switch dim {
+		case "":
+			continue
+		case GroupByPath, GroupByMethod, GroupByStatusClass, GroupByUser, GroupByTime:
+			q.groupBy = append(q.groupBy, dim)
+		default:
+			return fmt.Errorf("%w: unknown group_by %q (allowed: path, method, status_class, user, time)", ErrInvalidStatsQuery, dim)
+		}
+	}
+
+	if q.Bucket == "" {
+		q.Bucket = "hour"
+	}
+	bucket, ok := statsBuckets[q.Bucket]
+	if !ok {
+		return fmt.Errorf("%w: bucket must be minute, hour or day", ErrInvalidStatsQuery)
+	}
+	q.bucket = bucket
+
+	if q.Sort == "" {
+		q.Sort = "count"
+	}
+	if _, ok := statsSorts[q.Sort]; !ok {
+		return fmt.Errorf("%w: sort must be count, error_rate, p50, p95 or p99", ErrInvalidStatsQuery)
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultStatsLimit
+	}
+	if q.Limit > maxStatsLimit {
+		q.Limit = maxStatsLimit
+	}
+	return nil
+}
+
+// StatsResult holds statistics for a window, overall and per group.
+type StatsResult struct {
+	From    time.Time     `json:"from"`
+	To      time.Time     `json:"to"`
+	GroupBy []string      `json:"group_by"`
+	Bucket  string        `json:"bucket,omitempty"`
+	Total   StatsGroup    `json:"total"`
+	Groups  []*StatsGroup `json:"groups"`
+	// Truncated is set when more groups matched than Limit.
+	Truncated bool `json:"truncated"`
+	// LatencySampled is set when percentiles of the total or of a group were
+	// estimated from a sample because it held too many logs.
+	LatencySampled bool `json:"latency_sampled,omitempty"`
+}
+
+// StatsGroup holds statistics for one combination of group values. Errors
+// are 5xx responses; client errors are 4xx responses.
+type StatsGroup struct {
+	Key             map[string]interface{} `json:"key,omitempty"`
+	Count           int                    `json:"count"`
+	ErrorCount      int                    `json:"error_count"`
+	ErrorRate       float64                `json:"error_rate"`
+	ClientErrorRate float64                `json:"client_error_rate"`
+	Latency         LatencyStats           `json:"latency_ms"`
+
+	clientErrors int
+	latencySum   int64
+	// latencies are the sampled latencies percentiles are computed from,
+	// unless the database already computed them.
+	latencies   []int64
+	percentiles bool
+	sortKey     string
+	bucketStart time.Time
+}
+
+// LatencyStats summarises request latency in milliseconds.
+type LatencyStats struct {
+	Avg float64 `json:"avg"`
+	P50 int64   `json:"p50"`
+	P95 int64   `json:"p95"`
+	P99 int64   `json:"p99"`
+	Max int64   `json:"max"`
+}
+
+// Where the database cannot compute percentiles, they are computed from at
+// most maxLatencySamples latencies for the whole window and, when grouping,
+// at most maxLatencySamples / groups, but no fewer than
+// minGroupLatencySamples, per group. Larger groups are sampled evenly by ID;
+// every group keeps at least one latency. They are variables so tests can
+// lower them.
+var (
+	maxLatencySamples      = 100000
+	minGroupLatencySamples = 1000
+)
+
+// statsKeys are the group values of a stats row. Dimensions that are not
+// grouped by are left zero.
+type statsKeys struct {
+	Path        string
+	Method      string
+	StatusClass int
+	UserID      *uint
+	// Bucket numbers time buckets since the Unix epoch.
+	Bucket int64
+}
+
+// statsAggregate is one group of the stats query, aggregated in SQL.
+type statsAggregate struct {
+	Keys         statsKeys `gorm:"embedded"`
+	Requests     int
+	Errors       int
+	ClientErrors int
+	LatencySum   int64
+	LatencyMax   int64
+	// Percentiles are only aggregated in SQL on Postgres.
+	P50, P95, P99 *int64
+}
+
+// latencySample is the latency of one sampled log with its group values.
+type latencySample struct {
+	Keys      statsKeys `gorm:"embedded"`
+	LatencyMs int64
+}
+
+// statsAggregator combines the SQL aggregates into groups and, where the
+// database has no percentile function, computes percentiles from latency
+// samples.
+type statsAggregator struct {
+	query  *StatsQuery
+	total  StatsGroup
+	groups map[string]*StatsGroup
+}
+
+func newStatsAggregator(q *StatsQuery) *statsAggregator {
+	return &statsAggregator{query: q, groups: make(map[string]*StatsGroup)}
+}
+
+// addTotal adds the aggregate of the whole window.
+func (a *statsAggregator) addTotal(row statsAggregate) {
+	a.total.add(row)
+}
+
+// add adds the aggregate of one group.
+func (a *statsAggregator) add(row statsAggregate) {
+	a.group(row.Keys).add(row)
+}
+
+// addTotalSample adds a sampled latency of the whole window.
+func (a *statsAggregator) addTotalSample(sample latencySample) {
+	a.total.latencies = append(a.total.latencies, sample.LatencyMs)
+}
+
+// addSample adds a sampled latency to its group.
+func (a *statsAggregator) addSample(sample latencySample) {
+	group := a.group(sample.Keys)
+	group.latencies = append(group.latencies, sample.LatencyMs)
+}
+
+// groupSampleSize is how many latencies are sampled per group.
+func (a *statsAggregator) groupSampleSize() int {
+	if len(a.groups) == 0 {
+		return maxLatencySamples
+	}
+	return max(maxLatencySamples/len(a.groups), minGroupLatencySamples)
+}
+
+// group returns the group of keys, creating it on first use.
+func (a *statsAggregator) group(keys statsKeys) *StatsGroup {
+	key := make(map[string]interface{}, len(a.query.groupBy))
+	parts := make([]string, 0, len(a.query.groupBy))
+	var bucketStart time.Time
+	for _, dim := range a.query.groupBy {
+		var value interface{}
+		switch dim {
+		case GroupByPath:
+			value = keys.Path
+		case GroupByMethod:
+			value = keys.Method
+		case GroupByStatusClass:
+			value = fmt.Sprintf("%dxx", keys.StatusClass/100)
+		case GroupByUser:
+			if keys.UserID != nil {
+				value = *keys.UserID
+			}
+		case GroupByTime:
+			bucketStart = time.Unix(keys.Bucket*int64(a.query.bucket/time.Second), 0).UTC()
+			value = bucketStart
+		}
+		key[dim] = value
+		parts = append(parts, fmt.Sprint(value))
+	}
+
+	id := strings.Join(parts, "\x00")
+	group, ok := a.groups[id]
+	if !ok {
+		group = &StatsGroup{Key: key, sortKey: id, bucketStart: bucketStart}
+		a.groups[id] = group
+	}
+	return group
+}
+
+func (a *statsAggregator) result() *StatsResult {
+	q := a.query
+	res := &StatsResult{
+		From:    q.From,
+		To:      q.To,
+		GroupBy: q.groupBy,
+		Groups:  make([]*StatsGroup, 0, len(a.groups)),
+	}
+	if res.GroupBy == nil {
+		res.GroupBy = []string{}
+	}
+
+	res.LatencySampled = a.total.sampled()
+	a.total.finish()
+	res.Total = a.total
+
+	byTime := false
+	for _, dim := range q.groupBy {
+		if dim == GroupByTime {
+			byTime = true
+			res.Bucket = q.Bucket
+		}
+	}
+
+	for _, group := range a.groups {
+		res.LatencySampled = res.LatencySampled || group.sampled()
+		group.finish()
+		res.Groups = append(res.Groups, group)
+	}
+
+	less := statsSorts[q.Sort]
+	sort.Slice(res.Groups, func(i, j int) bool {
+		gi, gj := res.Groups[i], res.Groups[j]
+		if byTime && !gi.bucketStart.Equal(gj.bucketStart) {
+			return gi.bucketStart.Before(gj.bucketStart)
+		}
+		if less(gi, gj) != less(gj, gi) {
+			return less(gi, gj)
+		}
+		return gi.sortKey < gj.sortKey
+	})
+
+	if len(res.Groups) > q.Limit {
+		res.Groups = res.Groups[:q.Limit]
+		res.Truncated = true
+	}
+	return res
+}
+
+func (g *StatsGroup) add(row statsAggregate) {
+	g.Count += row.Requests
+	g.ErrorCount += row.Errors
+	g.clientErrors += row.ClientErrors
+	g.latencySum += row.LatencySum
+	if row.LatencyMax > g.Latency.Max {
+		g.Latency.Max = row.LatencyMax
+	}
+	if row.P50 != nil && row.P95 != nil && row.P99 != nil {
+		g.Latency.P50, g.Latency.P95, g.Latency.P99 = *row.P50, *row.P95, *row.P99
+		g.percentiles = true
+	}
+}
+
+// sampled reports whether the group's percentiles come from a sample of its
+// latencies.
+func (g *StatsGroup) sampled() bool {
+	return !g.percentiles && len(g.latencies) < g.Count
+}
+
+// finish computes rates, the average and, unless the database computed
+// them, percentiles from the sampled latencies.
+func (g *StatsGroup) finish() {
+	if g.Count == 0 {
+		return
+	}
+	g.ErrorRate = float64(g.ErrorCount) / float64(g.Count)
+	g.ClientErrorRate = float64(g.clientErrors) / float64(g.Count)
+	g.Latency.Avg = float64(g.latencySum) / float64(g.Count)
+
+	if !g.percentiles && len(g.latencies) > 0 {
+		sort.Slice(g.latencies, func(i, j int) bool { return g.latencies[i] < g.latencies[j] })
+		g.Latency.P50 = percentile(g.latencies, 50)
+		g.Latency.P95 = percentile(g.latencies, 95)
+		g.Latency.P99 = percentile(g.latencies, 99)
+	}
+	g.latencies = nil
+}
+
+// percentile returns the nearest-rank percentile of sorted values, the
+// definition Postgres' percentile_disc uses too.
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
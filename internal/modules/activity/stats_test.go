@@ -0,0 +1,112 @@
+package activity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"study1/internal/core/database"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestRepository(t *testing.T) ActivityRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("sqlite pool: %v", err)
+	}
+	// Every connection to ":memory:" opens a database of its own.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&ActivityLog{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewActivityRepository(&database.DB{DB: db})
+}
+
+// A rare, slow endpoint next to a busy, fast one must keep its own
+// percentiles when the busy one is sampled, so sort=p95 ranks it first.
+func TestStatsSamplesPercentilesPerGroup(t *testing.T) {
+	savedMax, savedMin := maxLatencySamples, minGroupLatencySamples
+	maxLatencySamples, minGroupLatencySamples = 40, 10
+	t.Cleanup(func() { maxLatencySamples, minGroupLatencySamples = savedMax, savedMin })
+
+	repo := openTestRepository(t)
+	now := time.Now()
+	var logs []ActivityLog
+	for i := 0; i < 500; i++ {
+		logs = append(logs, ActivityLog{Method: "GET", Path: "/busy", Status: 200, LatencyMs: int64(10 + i%10), CreatedAt: now.Add(-time.Minute)})
+	}
+	for _, latency := range []int64{800, 900, 1000} {
+		logs = append(logs, ActivityLog{Method: "GET", Path: "/rare", Status: 200, LatencyMs: latency, CreatedAt: now.Add(-time.Minute)})
+	}
+	if err := repo.CreateManys(logs); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewActivityService(repo).GetStats(StatsQuery{To: now, GroupBy: GroupByPath, Sort: "p95"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.LatencySampled {
+		t.Error("latency_sampled is not set although /busy was sampled")
+	}
+	if res.Total.Count != 503 || res.Total.Latency.P50 == 0 {
+		t.Errorf("total = %+v, want 503 requests with percentiles", res.Total)
+	}
+
+	tests := []struct {
+		path  string
+		count int
+		p50   int64
+		p95   int64
+	}{
+		{"/rare", 3, 900, 1000},
+		{"/busy", 500, 14, 19},
+	}
+	if len(res.Groups) != len(tests) {
+		t.Fatalf("got %d groups, want %d", len(res.Groups), len(tests))
+	}
+	for i, tt := range tests {
+		group := res.Groups[i]
+		if group.Key[GroupByPath] != tt.path {
+			t.Fatalf("group %d is %v, want %s", i, group.Key, tt.path)
+		}
+		if group.Count != tt.count {
+			t.Errorf("%s: count %d, want %d", tt.path, group.Count, tt.count)
+		}
+		// /busy is sampled, so its percentiles may deviate slightly.
+		if d := group.Latency.P50 - tt.p50; d < -1 || d > 1 {
+			t.Errorf("%s: p50 %d, want about %d", tt.path, group.Latency.P50, tt.p50)
+		}
+		if d := group.Latency.P95 - tt.p95; d < -1 || d > 1 {
+			t.Errorf("%s: p95 %d, want about %d", tt.path, group.Latency.P95, tt.p95)
+		}
+	}
+}
+
+func TestMySQLTimeBucketsUseConnectionLocation(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "u:p@tcp(127.0.0.1:1)/db?parseTime=True&loc=Asia%2FJakarta", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewActivityRepository(&database.DB{DB: db})
+
+	expr, _ := repo.statsKeySQL(GroupByTime, time.Hour, time.Now())
+	// Jakarta is UTC+7: wall-clock seconds minus 25200 are Unix seconds.
+	if want := "(TIMESTAMPDIFF(SECOND, '1970-01-01 00:00:00', created_at) - 25200) DIV 3600"; expr != want {
+		t.Errorf("bucket = %s, want %s", expr, want)
+	}
+	if strings.Contains(expr, "UNIX_TIMESTAMP") {
+		t.Error("bucket depends on the session time zone")
+	}
+}
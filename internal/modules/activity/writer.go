@@ -0,0 +1,168 @@
+package activity
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WriterConfig controls buffering of activity log writes.
+type WriterConfig struct {
+	// BufferSize is the capacity of the queue between requests and the writer.
+	BufferSize int
+	// BatchSize flushes as soon as this many entries are pending.
+	BatchSize int
+	// FlushInterval flushes pending entries at least this often.
+	FlushInterval time.Duration
+}
+
+// LogStore stores batches of activity logs, e.g. *ActivityRepository.
+type LogStore interface {
+	CreateManys(logs []ActivityLog) error
+}
+
+// Writer writes activity logs in batches from a background goroutine so
+// requests do not wait on the database. When the buffer is full new entries
+// are dropped and counted rather than blocking the request.
+type Writer struct {
+	repo LogStore
+	cfg  WriterConfig
+
+	entries chan ActivityLog
+	done    chan struct{}
+
+	mu      sync.RWMutex
+	started bool
+	closed  bool
+
+	dropped         atomic.Uint64
+	reportedDropped uint64
+}
+
+// NewWriter creates a Writer. Call Start to begin flushing.
+func NewWriter(repo LogStore, cfg WriterConfig) *Writer {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1024
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	return &Writer{
+		repo:    repo,
+		cfg:     cfg,
+		entries: make(chan ActivityLog, cfg.BufferSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start launches the background flush loop.
+func (w *Writer) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started || w.closed {
+		return
+	}
+	w.started = true
+	go w.run()
+}
+
+// Write queues an entry without blocking. It returns false when the entry was
+// dropped because the buffer is full or the writer is closed.
+func (w *Writer) Write(entry ActivityLog) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		w.dropped.Add(1)
+		return false
+	}
+
+	select {
+	case w.entries <- entry:
+		return true
+	default:
+		w.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped returns how many entries have been dropped since the writer was
+// created.
+func (w *Writer) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close stops accepting entries and waits until the queued ones are written
+// or ctx is done.
+func (w *Writer) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.entries)
+	started := w.started
+	w.mu.Unlock()
+
+	if !started {
+		// Nothing is consuming the queue; drain it here.
+		w.run()
+		return nil
+	}
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]ActivityLog, 0, w.cfg.BatchSize)
+	flush := func() {
+		w.reportDropped()
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.repo.CreateManys(batch); err != nil {
+			log.Printf("⚠️  Failed to write %d activity log(s): %v", len(batch), err)
+		}
+		batch = make([]ActivityLog, 0, w.cfg.BatchSize)
+	}
+
+	for {
+		select {
+		case entry, ok := <-w.entries:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= w.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// reportDropped logs how many entries were dropped since the last report.
+func (w *Writer) reportDropped() {
+	total := w.dropped.Load()
+	if n := total - w.reportedDropped; n > 0 {
+		log.Printf("⚠️  Activity log buffer full, dropped %d entries (%d total)", n, total)
+		w.reportedDropped = total
+	}
+}
@@ -0,0 +1,91 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStore records the size of every batch written to it.
+type fakeStore struct {
+	mu      sync.Mutex
+	batches []int
+}
+
+func (s *fakeStore) CreateManys(logs []ActivityLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, len(logs))
+	return nil
+}
+
+func (s *fakeStore) batchSizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.batches)
+}
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    WriterConfig
+		writes int
+		start  bool
+		// flushed is set when the batches are written before Close.
+		flushed     bool
+		wantBatches []int
+		wantDropped uint64
+	}{
+		{"flushes a full batch", WriterConfig{BufferSize: 10, BatchSize: 3, FlushInterval: time.Hour}, 3, true, true, []int{3}, 0},
+		{"flushes on the interval", WriterConfig{BufferSize: 10, BatchSize: 100, FlushInterval: 20 * time.Millisecond}, 2, true, true, []int{2}, 0},
+		{"counts drops when the buffer is full", WriterConfig{BufferSize: 2, BatchSize: 100, FlushInterval: time.Hour}, 5, false, false, []int{2}, 3},
+		{"close drains pending entries", WriterConfig{BufferSize: 10, BatchSize: 100, FlushInterval: time.Hour}, 5, true, false, []int{5}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{}
+			w := NewWriter(store, tt.cfg)
+			// Queue everything before the loop starts, so an interval flush
+			// cannot split the entries.
+			for i := 0; i < tt.writes; i++ {
+				w.Write(ActivityLog{Path: fmt.Sprintf("/%d", i)})
+			}
+			if tt.start {
+				w.Start()
+			}
+
+			if tt.flushed {
+				deadline := time.Now().Add(2 * time.Second)
+				for len(store.batchSizes()) < len(tt.wantBatches) {
+					if time.Now().After(deadline) {
+						t.Fatalf("batches %v before Close, want %v", store.batchSizes(), tt.wantBatches)
+					}
+					time.Sleep(5 * time.Millisecond)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := w.Close(ctx); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			if got := store.batchSizes(); !slices.Equal(got, tt.wantBatches) {
+				t.Errorf("batches = %v, want %v", got, tt.wantBatches)
+			}
+			if got := w.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped() = %d, want %d", got, tt.wantDropped)
+			}
+
+			// A closed writer drops and counts everything.
+			if w.Write(ActivityLog{}) {
+				t.Error("Write after Close was accepted")
+			}
+			if got := w.Dropped(); got != tt.wantDropped+1 {
+				t.Errorf("Dropped() after Close = %d, want %d", got, tt.wantDropped+1)
+			}
+		})
+	}
+}
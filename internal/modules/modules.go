@@ -0,0 +1,9 @@
+// Package modules links every application module into a binary. Importing it
+// runs the modules' init functions, which register their models, migrations
+// and seeders. Add new modules to the import list below.
+package modules
+
+import (
+	_ "study1/internal/modules/activity"
+	_ "study1/internal/modules/user"
+)
@@ -28,7 +28,9 @@ type UserResponse struct {
 	Email     string    `json:"email"`
 	Age       int       `json:"age"`
 	CreatedAt time.Time `json:"created_at"`
+	CreatedBy uint      `json:"created_by"`
 	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy uint      `json:"updated_by"`
 }
 
 // ToResponse converts a User model to a UserResponse DTO.
@@ -40,6 +42,8 @@ func (u *User) ToResponse() UserResponse {
 		Email:     u.Email,
 		Age:       u.Age,
 		CreatedAt: u.CreatedAt,
+		CreatedBy: u.CreatedBy,
 		UpdatedAt: u.UpdatedAt,
+		UpdatedBy: u.UpdatedBy,
 	}
 }
@@ -1,8 +1,10 @@
 package user
 
 import (
+	"errors"
 	"net/http"
 
+	httpmw "study1/internal/core/http/middleware"
 	"study1/internal/core/types"
 
 	"github.com/gin-gonic/gin"
@@ -24,8 +26,9 @@ func (h *UserHandler) RegisterRoutes(router *gin.RouterGroup) {
 	{
 		users.GET("", h.GetManys)
 		users.GET(":uuid", h.GetOnes)
-		users.POST("", h.CreateOnes)
-		users.PUT(":uuid", h.UpdateOnes)
+		// Bodies of writes are kept in the activity log, redacted
+		users.POST("", httpmw.CaptureBody(), h.CreateOnes)
+		users.PUT(":uuid", httpmw.CaptureBody(), h.UpdateOnes)
 		users.DELETE(":uuid", h.DeleteOnes)
 	}
 }
@@ -36,8 +39,13 @@ func (h *UserHandler) RegisterRoutes(router *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Param search query string false "Search term"
+// @Param fields query string false "Comma-separated JSON fields to return, e.g. name,email"
 // @Param page query int false "Page number"
 // @Param page_size query int false "Page size"
+// @Param skip_count query bool false "Leave out total and pages"
+// @Param limit query int false "Page size for cursor pagination (default 10, max 100)"
+// @Param cursor query string false "next_cursor or prev_cursor from a previous response"
+// @Param filter query string false "Filters as filter[field][op]=value; op is eq (default), ne, gt, gte, lt, lte, like, in, nin, between or null, e.g. filter[age][gte]=18"
 // @Success 200 {object} types.Response
 // @Failure 400 {object} types.Response
 // @Failure 500 {object} types.Response
@@ -48,16 +56,33 @@ func (h *UserHandler) GetManys(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, types.NewErrorResponse(err.Error()))
 		return
 	}
+	if err := params.BindFilters(c.Request.URL.Query()); err != nil {
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse(err.Error()))
+		return
+	}
 
 	params.SetDefaultPagination()
 
 	users, meta, err := h.service.GetManys(params)
+	if errors.Is(err, types.ErrInvalidQuery) {
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse(err.Error()))
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.NewErrorResponse(err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, types.NewSuccessResponse(users, meta))
+	// Only the requested fields are sent, not zero values for the rest
+	var data interface{} = users
+	if keys := params.FieldList(); keys != nil {
+		if data, err = types.SelectFields(users, keys); err != nil {
+			c.JSON(http.StatusInternalServerError, types.NewErrorResponse(err.Error()))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse(data, meta))
 }
 
 // @Summary Get a user
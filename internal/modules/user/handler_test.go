@@ -0,0 +1,113 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(t *testing.T) (*gin.Engine, UserService) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	service, _ := newTestService(t)
+	router := gin.New()
+	NewUserHandler(service).RegisterRoutes(router.Group("/"))
+	return router, service
+}
+
+func getUsers(router *gin.Engine, query url.Values) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users?"+query.Encode(), nil)
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetManysValidatesQuery(t *testing.T) {
+	router, service := newTestRouter(t)
+	if _, err := service.CreateManys(context.Background(), []CreateUserRequest{
+		{Name: "Andi", Email: "andi@example.com", Age: 20},
+		{Name: "Budi", Email: "budi@example.com", Age: 30},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		param string
+		value string
+		want  int
+	}{
+		{"selectable fields", "fields", "name,email", http.StatusOK},
+		{"audit fields", "fields", "created_by,updated_by", http.StatusOK},
+		{"unknown field", "fields", "password", http.StatusBadRequest},
+		{"hidden field", "fields", "deleted_at", http.StatusBadRequest},
+		{"untagged field", "fields", "deleted_by", http.StatusBadRequest},
+		{"column name instead of JSON name", "fields", "CreatedBy", http.StatusBadRequest},
+		{"injected field", "fields", "name, (SELECT 1)", http.StatusBadRequest},
+		{"sortable field", "sort", "-age,name", http.StatusOK},
+		{"explicit direction", "sort", "name desc", http.StatusOK},
+		{"not sortable", "sort", "created_by", http.StatusBadRequest},
+		{"unknown sort field", "sort", "password", http.StatusBadRequest},
+		{"bad direction", "sort", "name sideways", http.StatusBadRequest},
+		{"injected statement", "sort", "name; DROP TABLE users", http.StatusBadRequest},
+		{"injected expression", "sort", "(CASE WHEN 1=1 THEN name END)", http.StatusBadRequest},
+		{"injected null placement", "sort", "name:nulls_first; DROP TABLE users", http.StatusBadRequest},
+		{"filterable field", "filter[age][gte]", "18", http.StatusOK},
+		{"audit filter", "filter[created_by]", "0", http.StatusOK},
+		{"not filterable", "filter[deleted_by]", "0", http.StatusBadRequest},
+		{"unknown filter field", "filter[password]", "x", http.StatusBadRequest},
+		{"unknown operator", "filter[age][approx]", "18", http.StatusBadRequest},
+		{"bad filter value", "filter[age]", "old", http.StatusBadRequest},
+		{"bad between arity", "filter[age][between]", "18", http.StatusBadRequest},
+		{"bad null value", "filter[name][null]", "maybe", http.StatusBadRequest},
+		{"injected filter column", "filter[age) OR (1=1]", "1", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := getUsers(router, url.Values{tt.param: {tt.value}})
+			if rec.Code != tt.want {
+				t.Errorf("%s=%q: status %d, want %d: %s", tt.param, tt.value, rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+
+	// The rejected queries must not have reached the database.
+	if rec := getUsers(router, nil); rec.Code != http.StatusOK {
+		t.Fatalf("listing users after the injection attempts: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestGetManysReturnsOnlySelectedFields(t *testing.T) {
+	router, service := newTestRouter(t)
+	if _, err := service.CreateOnes(CreateUserRequest{Name: "Andi", Email: "andi@example.com", Age: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := getUsers(router, url.Values{"fields": {"name,created_by"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var body struct {
+		Data []map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Data) != 1 {
+		t.Fatalf("got %d users, want 1", len(body.Data))
+	}
+	var keys []string
+	for key := range body.Data[0] {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	if want := []string{"created_by", "name"}; !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+}
@@ -1,20 +1,25 @@
 package user
 
 import (
+	"study1/internal/core/database"
 	"study1/internal/core/types"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+func init() {
+	database.RegisterModels(ModuleName, &User{})
+}
+
 // User represents a user entity in the system.
 // This model is used for database operations and API responses.
 // @Description User model stored in DB and used in responses
 type User struct {
 	types.BaseModel
-	Name  string `gorm:"size:100;not null;column:name" json:"name" searchable:"true"`
-	Email string `gorm:"size:100;uniqueIndex:idx_users_email;not null;column:email" json:"email" searchable:"true"`
-	Age   int    `gorm:"type:int;default:0;column:age" json:"age"`
+	Name  string `gorm:"size:100;not null;column:name" json:"name" searchable:"true" sortable:"true" filterable:"true" selectable:"true"`
+	Email string `gorm:"size:100;uniqueIndex:idx_users_email;not null;column:email" json:"email" searchable:"true" sortable:"true" filterable:"true" selectable:"true"`
+	Age   int    `gorm:"type:int;default:0;column:age" json:"age" sortable:"true" filterable:"true" selectable:"true"`
 	types.RecordModel
 	types.SoftDeleteModel
 }
@@ -24,6 +29,11 @@ func (User) TableName() string {
 	return "users"
 }
 
+// DefaultSort lists newest first.
+func (User) DefaultSort() string {
+	return "-created_at"
+}
+
 // BeforeCreate hook populates UUID if not set.
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.UUID == "" {
@@ -1,12 +1,25 @@
 package user
 
 import (
+	"study1/internal/core/config"
 	"study1/internal/core/database"
+	"study1/internal/core/module"
 
 	"github.com/gin-gonic/gin"
 )
 
+// ModuleName identifies the user module in the model and module registries.
+const ModuleName = "user"
+
+func init() {
+	module.Register(func(cfg *config.Config, db *database.DB) module.Module {
+		return NewUserModule(db)
+	})
+}
+
 type UserModule struct {
+	module.Base
+
 	Repository UserRepository
 	Service    UserService
 	Handler    *UserHandler
@@ -14,10 +27,11 @@ type UserModule struct {
 
 func NewUserModule(db *database.DB) *UserModule {
 	repo := NewUserRepository(db)
-	service := NewUserService(repo)
+	service := NewUserService(repo, db)
 	handler := NewUserHandler(service)
 
 	return &UserModule{
+		Base:       module.Base{ModuleName: ModuleName, DB: db},
 		Repository: repo,
 		Service:    service,
 		Handler:    handler,
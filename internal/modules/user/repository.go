@@ -22,7 +22,8 @@ type userRepository struct {
 	db          *database.DB
 }
 
-// NewUserRepository creates a new instance of UserRepository.
+// NewUserRepository creates a new instance of UserRepository. db may be a
+// transaction from database.WithTransaction.
 func NewUserRepository(db *database.DB) UserRepository {
 	return &userRepository{
 		genericRepo: repository.NewGenericRepositoryWithSoftDelete[User](db, true),
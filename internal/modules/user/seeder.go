@@ -0,0 +1,57 @@
+package user
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"study1/internal/core/database"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterSeeder(Seeder{Count: 20})
+}
+
+var (
+	seedFirstNames = []string{"Andi", "Budi", "Citra", "Dewi", "Eka", "Fajar", "Gita", "Hadi", "Indah", "Joko"}
+	seedLastNames  = []string{"Pratama", "Saputra", "Wijaya", "Lestari", "Santoso", "Kurniawan", "Putri", "Hidayat"}
+)
+
+// Seeder creates fake users for local development. Users are keyed by email,
+// so running the seeder again does not create duplicates.
+type Seeder struct {
+	Count int
+}
+
+// Name returns the seeder name used by `migrate seed users`.
+func (Seeder) Name() string {
+	return "users"
+}
+
+// Models returns the models the seeder writes.
+func (Seeder) Models() []interface{} {
+	return []interface{}{&User{}}
+}
+
+// Run inserts Count fake users that do not exist yet.
+func (s Seeder) Run(db *gorm.DB) error {
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 1; i <= s.Count; i++ {
+		first := seedFirstNames[rng.Intn(len(seedFirstNames))]
+		last := seedLastNames[rng.Intn(len(seedLastNames))]
+
+		user := User{
+			Name:  first + " " + last,
+			Email: fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i),
+			Age:   18 + rng.Intn(50),
+		}
+
+		if err := db.Where("email = ?", user.Email).FirstOrCreate(&user).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package user
 
 import (
+	"context"
 	"errors"
+	"study1/internal/core/database"
 	"study1/internal/core/types"
 )
 
@@ -9,22 +11,33 @@ import (
 type UserService interface {
 	GetManys(params types.QueryParams) ([]UserResponse, *types.Meta, error)
 	GetOnes(uuid string) (*UserResponse, error)
-	CreateManys(req []CreateUserRequest) ([]UserResponse, error)
+	CreateManys(ctx context.Context, req []CreateUserRequest) ([]UserResponse, error)
 	CreateOnes(req CreateUserRequest) (*UserResponse, error)
-	UpdateManys(req []UpdateUserRequest) ([]UserResponse, error)
+	UpdateManys(ctx context.Context, req []UpdateUserRequest) ([]UserResponse, error)
 	UpdateOnes(uuid string, req UpdateUserRequest) (*UserResponse, error)
-	DeleteManys(uuids []string) error
+	DeleteManys(ctx context.Context, uuids []string) error
 	DeleteOnes(uuid string) error
 }
 
 // userService implements the UserService interface.
 type userService struct {
 	repo UserRepository
+	db   *database.DB
 }
 
-// NewUserService creates a new instance of UserService.
-func NewUserService(repo UserRepository) UserService {
-	return &userService{repo: repo}
+// NewUserService creates a new instance of UserService. db is used to run
+// the *Manys operations in a transaction.
+func NewUserService(repo UserRepository, db *database.DB) UserService {
+	return &userService{repo: repo, db: db}
+}
+
+// inTransaction runs fn with a service whose repository works on one
+// transaction, so a batch is saved completely or not at all. The transaction
+// is cancelled with ctx.
+func (s *userService) inTransaction(ctx context.Context, fn func(tx *userService) error) error {
+	return database.WithTransaction(ctx, s.db, func(tx *database.DB) error {
+		return fn(&userService{repo: NewUserRepository(tx), db: tx})
+	})
 }
 
 // GetManys users retrieves all users with pagination and filtering.
@@ -109,40 +122,56 @@ func (s *userService) UpdateOnes(uuid string, req UpdateUserRequest) (*UserRespo
 	return &response, nil
 }
 
-// CreateManys creates multiple users by delegating to CreateOnes for each request.
-func (s *userService) CreateManys(reqs []CreateUserRequest) ([]UserResponse, error) {
+// CreateManys creates multiple users in one transaction; if any of them
+// fails, none are created.
+func (s *userService) CreateManys(ctx context.Context, reqs []CreateUserRequest) ([]UserResponse, error) {
 	responses := make([]UserResponse, 0, len(reqs))
-	for _, r := range reqs {
-		resp, err := s.CreateOnes(r)
-		if err != nil {
-			return nil, err
+	err := s.inTransaction(ctx, func(tx *userService) error {
+		for _, r := range reqs {
+			resp, err := tx.CreateOnes(r)
+			if err != nil {
+				return err
+			}
+			responses = append(responses, *resp)
 		}
-		responses = append(responses, *resp)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return responses, nil
 }
 
-func (s *userService) UpdateManys(reqs []UpdateUserRequest) ([]UserResponse, error) {
+// UpdateManys updates multiple users in one transaction; if any update
+// fails, none are applied.
+func (s *userService) UpdateManys(ctx context.Context, reqs []UpdateUserRequest) ([]UserResponse, error) {
 	responses := make([]UserResponse, 0, len(reqs))
-	for _, r := range reqs {
-		resp, err := s.UpdateOnes(r.UUID, r)
-		if err != nil {
-			return nil, err
+	err := s.inTransaction(ctx, func(tx *userService) error {
+		for _, r := range reqs {
+			resp, err := tx.UpdateOnes(r.UUID, r)
+			if err != nil {
+				return err
+			}
+			responses = append(responses, *resp)
 		}
-		responses = append(responses, *resp)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
 	return responses, nil
 }
 
-// DeleteManys deletes multiple users by calling DeleteOnes for each UUID.
-func (s *userService) DeleteManys(uuids []string) error {
-	for _, u := range uuids {
-		if err := s.DeleteOnes(u); err != nil {
-			return err
+// DeleteManys deletes multiple users in one transaction.
+func (s *userService) DeleteManys(ctx context.Context, uuids []string) error {
+	return s.inTransaction(ctx, func(tx *userService) error {
+		for _, u := range uuids {
+			if err := tx.DeleteOnes(u); err != nil {
+				return err
+			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // DeleteUser removes a user by their ID.
@@ -0,0 +1,80 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"study1/internal/core/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestService(t *testing.T) (UserService, *database.DB) {
+	t.Helper()
+	gdb, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		t.Fatalf("sqlite pool: %v", err)
+	}
+	// Every connection to ":memory:" opens a database of its own.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := gdb.AutoMigrate(&User{}); err != nil {
+		t.Fatal(err)
+	}
+
+	db := &database.DB{DB: gdb}
+	return NewUserService(NewUserRepository(db), db), db
+}
+
+func TestCreateManysRollsBackOnFailure(t *testing.T) {
+	service, db := newTestService(t)
+
+	_, err := service.CreateManys(context.Background(), []CreateUserRequest{
+		{Name: "Andi", Email: "andi@example.com", Age: 20},
+		{Name: "Budi", Email: "budi@example.com", Age: 30},
+		{Name: "Andi Again", Email: "andi@example.com", Age: 40},
+	})
+	if err == nil {
+		t.Fatal("CreateManys with a duplicate email succeeded")
+	}
+
+	var count int64
+	if err := db.Model(&User{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("%d users kept after the failed batch, want 0", count)
+	}
+}
+
+func TestUpdateManysRollsBackOnFailure(t *testing.T) {
+	service, db := newTestService(t)
+	created, err := service.CreateManys(context.Background(), []CreateUserRequest{
+		{Name: "Andi", Email: "andi@example.com", Age: 20},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.UpdateManys(context.Background(), []UpdateUserRequest{
+		{UUID: created[0].UUID, Name: "Renamed"},
+		{UUID: "00000000-0000-0000-0000-000000000000", Name: "Missing"},
+	})
+	if err == nil {
+		t.Fatal("UpdateManys with an unknown UUID succeeded")
+	}
+
+	var user User
+	if err := db.First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "Andi" {
+		t.Errorf("name = %q after the failed batch, want Andi", user.Name)
+	}
+}